@@ -0,0 +1,49 @@
+package ecs
+
+import (
+	"reflect"
+)
+
+// Access declares how a system uses a component (or resource) type, to be passed to ECS.AddSystem instead of the plain type
+type Access struct {
+	Type  reflect.Type
+	Write bool
+	// resources are not matched against entities and do not conflict with components of the same type
+	Resource bool
+}
+
+// accessKey identifies an accessed component or resource type
+type accessKey struct {
+	typ      reflect.Type
+	resource bool
+}
+
+// key returns the conflict key of this access, pointer and value forms share one key as they share one column
+func (this Access) key() accessKey {
+	return accessKey{typ: plainType(this.Type), resource: this.Resource}
+}
+
+// Read declares read-only access to component T, systems only reading T may run in parallel
+func Read[T any]() Access {
+	return Access{Type: reflect.TypeFor[T]()}
+}
+
+// Write declares read-write access to component T, systems writing T run exclusively
+func Write[T any]() Access {
+	return Access{Type: reflect.TypeFor[T](), Write: true}
+}
+
+// ReadResource declares read-only access to resource T, systems only reading T may run in parallel
+func ReadResource[T any]() Access {
+	return Access{Type: reflect.TypeFor[T](), Resource: true}
+}
+
+// WriteResource declares read-write access to resource T, systems writing T run exclusively
+func WriteResource[T any]() Access {
+	return Access{Type: reflect.TypeFor[T](), Write: true, Resource: true}
+}
+
+// AccessDeclarer may be implemented by systems to declare access to components they do not match entities on, or resources
+type AccessDeclarer interface {
+	Access() []Access
+}
@@ -0,0 +1,211 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+)
+
+// column is a dense slice of one component type inside an archetype
+type column struct {
+	typ reflect.Type
+	// ptr holds a *[]T, so the slice can grow in place and be handed out typed without allocation
+	ptr reflect.Value
+	ref any
+	// per row, the world ticks the component was added and last changed at
+	ticks []componentTicks
+}
+
+// componentTicks track when a component was added and last changed, for change detection
+type componentTicks struct {
+	added   uint64
+	changed uint64
+}
+
+func newColumn(typ reflect.Type) (this *column) {
+	this = new(column)
+	this.typ = typ
+	this.ptr = reflect.New(reflect.SliceOf(typ))
+	this.ref = this.ptr.Interface()
+	return this
+}
+
+// len returns the amount of stored components
+func (this *column) len() int {
+	return this.ptr.Elem().Len()
+}
+
+// append adds the component at the end of the column
+func (this *column) append(c any, ticks componentTicks) {
+	slice := this.ptr.Elem()
+	slice.Set(reflect.Append(slice, reflect.ValueOf(c)))
+	this.ticks = append(this.ticks, ticks)
+}
+
+// appendFrom appends the component and ticks at the given row of another column of the same type
+func (this *column) appendFrom(other *column, row int) {
+	slice := this.ptr.Elem()
+	slice.Set(reflect.Append(slice, other.ptr.Elem().Index(row)))
+	this.ticks = append(this.ticks, other.ticks[row])
+}
+
+// get returns the component at the given row
+func (this *column) get(row int) any {
+	return this.ptr.Elem().Index(row).Interface()
+}
+
+// set replaces the component at the given row
+func (this *column) set(row int, c any) {
+	this.ptr.Elem().Index(row).Set(reflect.ValueOf(c))
+}
+
+// swapRemove moves the last component into the given row and shrinks the column by one
+func (this *column) swapRemove(row int) {
+	slice := this.ptr.Elem()
+	last := slice.Len() - 1
+	if row != last {
+		slice.Index(row).Set(slice.Index(last))
+	}
+	slice.Index(last).SetZero()
+	slice.SetLen(last)
+	this.ticks[row] = this.ticks[last]
+	this.ticks = this.ticks[:last]
+}
+
+// columnData returns the typed slice of a column, T must match the stored type exactly
+func columnData[T any](c *column) []T {
+	return *c.ref.(*[]T)
+}
+
+// Archetype groups all entities sharing the exact same set of component types
+type Archetype struct {
+	id       int
+	registry *ComponentRegistry
+	// the sorted raw component types of this archetype and their registry ids
+	types []reflect.Type
+	ids   []ComponentID
+	// per registry id the index into columns, -1 if not stored (or a tag)
+	index []int
+	// the membership bitset of all registry ids, including tags which have no column
+	mask     []uint64
+	columns  []*column
+	entities []uint64
+	// per raw tag type, the archetype with the tag toggled
+	edges map[reflect.Type]*Archetype
+}
+
+func newArchetype(id int, registry *ComponentRegistry, types []reflect.Type, ids []ComponentID) (this *Archetype) {
+	this = new(Archetype)
+	this.id = id
+	this.registry = registry
+	this.types = types
+	this.ids = ids
+	this.index = make([]int, slices.Max(ids)+1)
+	for i := range this.index {
+		this.index[i] = -1
+	}
+	this.mask = make([]uint64, slices.Max(ids)/64+1)
+	this.edges = make(map[reflect.Type]*Archetype)
+	for i, t := range types {
+		this.mask[ids[i]/64] |= 1 << (ids[i] % 64)
+		if registry.isTag(ids[i]) {
+			continue
+		}
+		this.index[ids[i]] = len(this.columns)
+		this.columns = append(this.columns, newColumn(t))
+	}
+	return this
+}
+
+// Id returns the storage-unique id of this archetype
+func (this *Archetype) Id() int {
+	return this.id
+}
+
+// Types returns the raw component types of this archetype
+func (this *Archetype) Types() []reflect.Type {
+	return this.types
+}
+
+// Entities returns the entity ids, in row order
+func (this *Archetype) Entities() []uint64 {
+	return this.entities
+}
+
+// Len returns the amount of entities in this archetype
+func (this *Archetype) Len() int {
+	return len(this.entities)
+}
+
+// Ids returns the registry ids of the component types of this archetype
+func (this *Archetype) Ids() []ComponentID {
+	return this.ids
+}
+
+// Has checks whether this archetype stores the given type, pointer or value
+func (this *Archetype) Has(t reflect.Type) bool {
+	id, ok := this.registry.Lookup(t)
+	return ok && this.HasId(id)
+}
+
+// HasId checks whether this archetype stores the given registry id
+func (this *Archetype) HasId(id ComponentID) bool {
+	return int(id/64) < len(this.mask) && this.mask[id/64]&(1<<(id%64)) != 0
+}
+
+// column returns the column of the given type or nil
+func (this *Archetype) column(t reflect.Type) *column {
+	if id, ok := this.registry.Lookup(t); ok {
+		return this.columnById(id)
+	}
+	return nil
+}
+
+// columnById returns the column of the given registry id or nil (also for tags)
+func (this *Archetype) columnById(id ComponentID) *column {
+	if int(id) < len(this.index) && this.index[id] >= 0 {
+		return this.columns[this.index[id]]
+	}
+	return nil
+}
+
+// component returns the component of the i-th type at the given row, a zero value for tags
+func (this *Archetype) component(i int, row int) any {
+	if c := this.columnById(this.ids[i]); c != nil {
+		return c.get(row)
+	}
+	return zeroComponent(this.types[i])
+}
+
+// add appends an entity row with the given components and their ticks, ordered by this.types (tags are dropped)
+func (this *Archetype) add(eId uint64, components []any, ticks []componentTicks) int {
+	for i, c := range components {
+		if col := this.columnById(this.ids[i]); col != nil {
+			col.append(c, ticks[i])
+		}
+	}
+	this.entities = append(this.entities, eId)
+	return len(this.entities) - 1
+}
+
+// remove swaps the given row out and returns the entity id which moved into the row (0 if none)
+func (this *Archetype) remove(row int) uint64 {
+	for _, c := range this.columns {
+		c.swapRemove(row)
+	}
+	last := len(this.entities) - 1
+	moved := uint64(0)
+	if row != last {
+		moved = this.entities[last]
+		this.entities[row] = moved
+	}
+	this.entities = this.entities[:last]
+	return moved
+}
+
+// zeroComponent returns the zero value of the raw type, a pointer to a new zero value for pointer types
+func zeroComponent(typ reflect.Type) any {
+	if typ.Kind() == reflect.Pointer {
+		return reflect.New(typ.Elem()).Interface()
+	}
+	return reflect.Zero(typ).Interface()
+}
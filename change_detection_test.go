@@ -0,0 +1,84 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+)
+
+type NetworkSystem struct {
+	EntitySystem
+	query *Query1[*PositionComponent]
+	sent  int
+}
+
+func (this *NetworkSystem) Run(ecs *ECS, dt time.Duration) {
+	for range this.query.Iter() {
+		this.sent++
+	}
+}
+
+func Test_Query_Changed(t *testing.T) {
+	ecs := New()
+
+	player1 := createPlayer("player1")
+	ecs.CreateEntity(&player1.PositionComponent, &player1.VelocityComponent)
+	player2 := createPlayer("player2")
+	ecs.CreateEntity(&player2.PositionComponent)
+
+	moveQuery := NewQuery2[*PositionComponent, *VelocityComponent](ecs)
+	ecs.AddSystem(&QueryMoveSystem{query: moveQuery}, moveQuery)
+	networkQuery := NewQuery1[*PositionComponent](ecs).Changed(PositionComponent{}).ReadOnly(PositionComponent{})
+	networkSystem := NetworkSystem{query: networkQuery}
+	ecs.AddSystem(&networkSystem, networkQuery)
+
+	// Assertions
+	ecs.Update(33 * time.Millisecond)
+	if networkSystem.sent != 2 {
+		t.Errorf("sent = %d; expected %d (all added)", networkSystem.sent, 2)
+	}
+	ecs.Update(33 * time.Millisecond)
+	if networkSystem.sent != 3 {
+		t.Errorf("sent = %d; expected %d (only moved)", networkSystem.sent, 3)
+	}
+	ecs.MarkChanged(ecs.CreateEntity(&PositionComponent{}).Id(), PositionComponent{})
+	ecs.Update(33 * time.Millisecond)
+	if networkSystem.sent != 5 {
+		t.Errorf("sent = %d; expected %d (moved and created)", networkSystem.sent, 5)
+	}
+}
+
+func Test_Query_Added(t *testing.T) {
+	ecs := New()
+
+	ecs.CreateEntity(&PositionComponent{})
+	query := NewQuery1[*PositionComponent](ecs).Added(PositionComponent{})
+
+	// Assertions
+	if query.Count() != 1 {
+		t.Errorf("count = %d; expected %d", query.Count(), 1)
+	}
+	query.Each(func(id uint64, p *PositionComponent) {})
+	ecs.Update(33 * time.Millisecond)
+	if query.Count() != 0 {
+		t.Errorf("count = %d; expected %d", query.Count(), 0)
+	}
+	ecs.CreateEntity(&PositionComponent{})
+	if query.Count() != 1 {
+		t.Errorf("count = %d; expected %d", query.Count(), 1)
+	}
+}
+
+func Test_Query_Changed_System(t *testing.T) {
+	ecs := New()
+
+	// Change filters match entities by the given raw types, like With
+	query := NewQuery1[*VelocityComponent](ecs).Changed(&PositionComponent{})
+	system := QueryMoveSystem{}
+	ecs.AddSystem(&system, query)
+	ecs.CreateEntity(&PositionComponent{}, &VelocityComponent{})
+
+	// Assertions
+	if len(system.Entities()) != 1 || query.Count() != 1 {
+		t.Errorf("entities(%d, %d); expected (%d, %d)", len(system.Entities()), query.Count(), 1, 1)
+	}
+}
@@ -0,0 +1,85 @@
+package ecs
+
+type commandKind int
+
+const (
+	commandCreateEntity commandKind = iota
+	commandRemoveEntity
+	commandAddComponents
+	commandRemoveComponents
+	commandDetachEntity
+)
+
+// command is one recorded structural change
+type command struct {
+	kind       commandKind
+	entity     *BaseEntity
+	id         uint64
+	components []any
+	systems    []System
+}
+
+// CommandBuffer records structural changes to be played back later, e.g. at the sync points of ECS.Update
+type CommandBuffer struct {
+	ecs *ECS
+
+	commands []command
+}
+
+func NewCommandBuffer(ecs *ECS) (this *CommandBuffer) {
+	this = new(CommandBuffer)
+	this.ecs = ecs
+	return this
+}
+
+// CreateEntity records the creation of an entity with the given components and returns its reserved id
+func (this *CommandBuffer) CreateEntity(components ...any) uint64 {
+	entity := newEntity(this.ecs.entityIds.allocate())
+	this.commands = append(this.commands, command{kind: commandCreateEntity, entity: entity, components: components})
+	return entity.Id()
+}
+
+// RemoveEntity records the removal of an entity
+func (this *CommandBuffer) RemoveEntity(id uint64) {
+	this.commands = append(this.commands, command{kind: commandRemoveEntity, id: id})
+}
+
+// AddComponents records adding components to an entity
+func (this *CommandBuffer) AddComponents(id uint64, components ...any) {
+	this.commands = append(this.commands, command{kind: commandAddComponents, id: id, components: components})
+}
+
+// RemoveComponents records removing components (or types) from an entity
+func (this *CommandBuffer) RemoveComponents(id uint64, components ...any) {
+	this.commands = append(this.commands, command{kind: commandRemoveComponents, id: id, components: components})
+}
+
+// DetachEntity records detaching an entity from the given (or all) systems
+func (this *CommandBuffer) DetachEntity(id uint64, systems ...System) {
+	this.commands = append(this.commands, command{kind: commandDetachEntity, id: id, systems: systems})
+}
+
+// Len returns the amount of recorded commands
+func (this *CommandBuffer) Len() int {
+	return len(this.commands)
+}
+
+// Playback applies all recorded commands in order and resets the buffer
+func (this *CommandBuffer) Playback() {
+	for _, c := range this.commands {
+		switch c.kind {
+		case commandCreateEntity:
+			this.ecs.createEntity(c.entity, c.components...)
+		case commandRemoveEntity:
+			this.ecs.RemoveEntityNow(c.id)
+		case commandAddComponents:
+			this.ecs.AddComponents(c.id, c.components...)
+		case commandRemoveComponents:
+			this.ecs.RemoveComponents(c.id, c.components...)
+		case commandDetachEntity:
+			this.ecs.DetachEntityFromNow(c.id, c.systems...)
+		}
+	}
+	clear(this.commands)
+	this.commands = this.commands[:0]
+}
@@ -0,0 +1,74 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+)
+
+type SpawnSystem struct {
+	EntitySystem
+	spawned []uint64
+}
+
+func (this *SpawnSystem) Run(ecs *ECS, dt time.Duration) {
+	commands := ecs.Commands(this)
+	for _, entityId := range this.entities {
+		// Replace the spawner by a moving entity
+		commands.RemoveEntity(entityId)
+		this.spawned = append(this.spawned, commands.CreateEntity(&PositionComponent{X: 1, Y: 1}, &VelocityComponent{DX: 2, DY: 2}))
+	}
+}
+
+func Test_CommandBuffer(t *testing.T) {
+	ecs := NewParallel()
+	defer ecs.Close()
+
+	moveSystem := MoveSystem{}
+	spawnSystem := SpawnSystem{}
+	ecs.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
+	ecs.AddSystem(&spawnSystem, &CommComponent{})
+
+	spawner := ecs.CreateEntity(&CommComponent{})
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if ecs.GetEntity(spawner.Id()) != nil {
+		t.Errorf("spawner %d; expected to be removed", spawner.Id())
+	}
+	if len(spawnSystem.spawned) != 1 || ecs.GetEntity(spawnSystem.spawned[0]) == nil {
+		t.Errorf("spawned %v; expected one entity", spawnSystem.spawned)
+	}
+	if len(moveSystem.Entities()) != 1 || len(spawnSystem.Entities()) != 0 {
+		t.Errorf("entities (%d, %d); expected (%d, %d)", len(moveSystem.Entities()), len(spawnSystem.Entities()), 1, 0)
+	}
+
+	ecs.Update(33 * time.Millisecond)
+	if p := GetEntityComponent[*PositionComponent](ecs, spawnSystem.spawned[0]); p.X != 3 {
+		t.Errorf("position = %d; expected %d", p.X, 3)
+	}
+}
+
+func Test_CommandBuffer_Order(t *testing.T) {
+	ecs := New()
+	commands := NewCommandBuffer(ecs)
+
+	player := createPlayer("player")
+	id := commands.CreateEntity(&player.PositionComponent)
+	commands.AddComponents(id, &player.VelocityComponent)
+	commands.RemoveComponents(id, PositionComponent{})
+
+	// Nothing happens before playback
+	if ecs.GetEntity(id) != nil || commands.Len() != 3 {
+		t.Errorf("entity %d; expected to be deferred", id)
+	}
+
+	commands.Playback()
+
+	// Assertions
+	if ecs.GetEntity(id) == nil || commands.Len() != 0 {
+		t.Errorf("entity %d; expected to be created", id)
+	}
+	if len(ecs.GetEntity(id).GetComponents()) != 1 {
+		t.Errorf("components = %d; expected %d", len(ecs.GetEntity(id).GetComponents()), 1)
+	}
+}
@@ -1,68 +1,380 @@
 package ecs
 
 import (
+	"fmt"
+	"maps"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 )
 
+// entityLocation points to the archetype row an entity is stored in
+type entityLocation struct {
+	archetype *Archetype
+	row       int
+}
+
 type ComponentStorage struct {
 	ecs *ECS
 
-	// n components per type could be registered
-	components map[reflect.Type]map[uint64]any
+	// entities are grouped by their exact component type set into archetypes
+	archetypes []*Archetype
+	// per signature of sorted registry ids (and pointer flags), the archetype
+	signatures map[string]*Archetype
+	// per entity, its archetype and row
+	locations map[uint64]entityLocation
 }
 
 func NewComponentStorage(ecs *ECS) (this *ComponentStorage) {
 	this = new(ComponentStorage)
 	this.ecs = ecs
-	this.components = make(map[reflect.Type]map[uint64]any)
+	this.signatures = make(map[string]*Archetype)
+	this.locations = make(map[uint64]entityLocation)
 	return
 }
 
 func (this *ComponentStorage) Clear() {
-	this.components = nil
+	this.archetypes = nil
+	this.signatures = nil
+	this.locations = nil
+}
+
+// Archetypes returns all archetypes of this storage
+func (this *ComponentStorage) Archetypes() []*Archetype {
+	return this.archetypes
 }
 
-// AddComponent stores the given components
+// AddComponent stores the given components, moving the entity into the matching archetype
 func (this *ComponentStorage) AddComponent(e Entity, components ...any) {
+	// Collect the current components by id, given components overwrite existing ones
+	current := this.entityComponents(e.Id())
+	before := maps.Clone(current)
+	added := make(map[ComponentID]any)
+	replaced := make(map[ComponentID]any)
 	for _, c := range components {
-		cType := this.ecs.getPlainType(c)
-		if _, ok := this.components[cType]; !ok {
-			this.components[cType] = make(map[uint64]any)
+		if c == nil {
+			continue
+		}
+		id := this.ecs.registry.Id(c)
+		current[id] = c
+		if _, ok := before[id]; ok {
+			replaced[id] = c
+		} else {
+			added[id] = c
 		}
-		this.components[cType][e.Id()] = c
+	}
+
+	// Replaced components count as changed, new ones get fresh ticks
+	ticks := this.entityTicks(e.Id())
+	for id := range replaced {
+		ticks[id] = componentTicks{added: ticks[id].added, changed: this.ecs.tick}
+	}
+	this.move(e.Id(), current, ticks)
+
+	// Notify about the changes
+	if this.ecs.hasLifecycleListeners() {
+		this.ecs.fireObservers(e.Id(), before, current)
+		this.ecs.fireAdded(e.Id(), added)
+		this.ecs.fireReplaced(e.Id(), before, replaced)
 	}
 }
 
 // RemoveComponent deletes the given components from their respective types and entity
 func (this *ComponentStorage) RemoveComponent(e Entity, components ...any) {
+	if _, ok := this.locations[e.Id()]; !ok {
+		return
+	}
+	current := this.entityComponents(e.Id())
+	removed := make(map[ComponentID]any)
 	for _, c := range components {
-		cType := this.ecs.getPlainType(c)
-		delete(this.components[cType], e.Id())
+		if c == nil {
+			continue
+		}
+		if id, ok := this.ecs.registry.Lookup(c); ok {
+			if old, ok := current[id]; ok {
+				removed[id] = old
+			}
+		}
+	}
+
+	// Notify before the components vanish
+	if this.ecs.hasLifecycleListeners() {
+		this.ecs.fireRemoved(e.Id(), removed)
+	}
+
+	before := maps.Clone(current)
+	for id := range removed {
+		delete(current, id)
+	}
+	this.move(e.Id(), current, this.entityTicks(e.Id()))
+
+	if this.ecs.hasLifecycleListeners() {
+		this.ecs.fireObservers(e.Id(), before, current)
 	}
 }
 
-// GetComponents by given type
+// GetComponents by given type (compatibility shim, builds a new map on every call)
 func (this *ComponentStorage) GetComponents(componentType any) map[uint64]interface{} {
-	return this.components[this.ecs.getPlainType(componentType)]
+	components := make(map[uint64]interface{})
+	id, ok := this.ecs.registry.Lookup(componentType)
+	if !ok {
+		return components
+	}
+	for _, a := range this.archetypes {
+		if !a.HasId(id) {
+			continue
+		}
+		i := slices.Index(a.ids, id)
+		for row, eId := range a.entities {
+			components[eId] = a.component(i, row)
+		}
+	}
+	return components
 }
 
-// GetEntityComponent is a typed helper to get a cast entity component from the ECS
+// GetComponent returns the component of the given type for the entity, if any
+func (this *ComponentStorage) GetComponent(eId uint64, componentType any) (any, bool) {
+	loc, ok := this.locations[eId]
+	if !ok {
+		return nil, false
+	}
+	id, ok := this.ecs.registry.Lookup(componentType)
+	if !ok || !loc.archetype.HasId(id) {
+		return nil, false
+	}
+	return loc.archetype.component(slices.Index(loc.archetype.ids, id), loc.row), true
+}
+
+// entityComponents returns the stored components of an entity by registry id
+func (this *ComponentStorage) entityComponents(eId uint64) map[ComponentID]any {
+	components := make(map[ComponentID]any)
+	if loc, ok := this.locations[eId]; ok {
+		for i, id := range loc.archetype.ids {
+			components[id] = loc.archetype.component(i, loc.row)
+		}
+	}
+	return components
+}
+
+// entityTicks returns the change detection ticks of an entity's components by registry id
+func (this *ComponentStorage) entityTicks(eId uint64) map[ComponentID]componentTicks {
+	ticks := make(map[ComponentID]componentTicks)
+	if loc, ok := this.locations[eId]; ok {
+		for _, id := range loc.archetype.ids {
+			if c := loc.archetype.columnById(id); c != nil {
+				ticks[id] = c.ticks[loc.row]
+			}
+		}
+	}
+	return ticks
+}
+
+// MarkChanged sets the changed tick of the entity's component of the given type to now
+func (this *ComponentStorage) MarkChanged(eId uint64, componentType any) {
+	loc, ok := this.locations[eId]
+	if !ok {
+		return
+	}
+	if c := loc.archetype.column(plainTypeOf(componentType)); c != nil {
+		c.ticks[loc.row].changed = this.ecs.tick
+	}
+}
+
+// move takes the entity out of its current archetype and into the one matching the given components,
+// keeping the given ticks (components without get the current tick)
+func (this *ComponentStorage) move(eId uint64, components map[ComponentID]any, ticks map[ComponentID]componentTicks) {
+	// Take out of the old archetype
+	if loc, ok := this.locations[eId]; ok {
+		if moved := loc.archetype.remove(loc.row); moved != 0 {
+			this.locations[moved] = entityLocation{archetype: loc.archetype, row: loc.row}
+		}
+		delete(this.locations, eId)
+	}
+	if len(components) == 0 {
+		return
+	}
+
+	// Find or create the new archetype, sorted by id
+	ids := make([]ComponentID, 0, len(components))
+	for id := range components {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	types := make([]reflect.Type, len(ids))
+	for i, id := range ids {
+		types[i] = reflect.TypeOf(components[id])
+	}
+	archetype := this.archetype(types, ids)
+
+	// Insert in column order
+	row := make([]any, len(ids))
+	rowTicks := make([]componentTicks, len(ids))
+	for i, id := range ids {
+		row[i] = components[id]
+		if t, ok := ticks[id]; ok {
+			rowTicks[i] = t
+		} else {
+			rowTicks[i] = componentTicks{added: this.ecs.tick, changed: this.ecs.tick}
+		}
+	}
+	this.locations[eId] = entityLocation{archetype: archetype, row: archetype.add(eId, row, rowTicks)}
+}
+
+// toggleTag moves the entity into the archetype with the given tag added or removed, copying its columns directly
+func (this *ComponentStorage) toggleTag(eId uint64, tag any, on bool) {
+	loc, ok := this.locations[eId]
+	id := this.ecs.registry.Id(tag)
+	if !ok {
+		// Entities without any component are not stored yet
+		if on {
+			this.move(eId, map[ComponentID]any{id: tag}, nil)
+		}
+		return
+	}
+	if loc.archetype.HasId(id) == on {
+		return
+	}
+
+	source := loc.archetype
+	target, ok := source.edges[typeOf(tag)]
+	if !ok {
+		ids, types := slices.Clone(source.ids), slices.Clone(source.types)
+		if i, found := slices.BinarySearch(ids, id); on {
+			ids, types = slices.Insert(ids, i, id), slices.Insert(types, i, typeOf(tag))
+		} else if found {
+			ids, types = slices.Delete(ids, i, i+1), slices.Delete(types, i, i+1)
+		}
+		if len(ids) == 0 {
+			// Without any component left, the entity is not stored at all
+			this.move(eId, nil, nil)
+			return
+		}
+		target = this.archetype(types, ids)
+		source.edges[typeOf(tag)] = target
+	}
+
+	for _, cId := range target.ids {
+		if c := target.columnById(cId); c != nil {
+			c.appendFrom(source.columnById(cId), loc.row)
+		}
+	}
+	target.entities = append(target.entities, eId)
+	if moved := source.remove(loc.row); moved != 0 {
+		this.locations[moved] = entityLocation{archetype: source, row: loc.row}
+	}
+	this.locations[eId] = entityLocation{archetype: target, row: target.Len() - 1}
+}
+
+// archetype returns the archetype of exactly the given raw types sorted by their ids, creating it if necessary
+func (this *ComponentStorage) archetype(types []reflect.Type, ids []ComponentID) *Archetype {
+	// Pointer and value forms share an id, but are stored in different archetypes
+	var signature strings.Builder
+	for i, id := range ids {
+		signature.WriteString(strconv.Itoa(int(id)))
+		if types[i].Kind() == reflect.Pointer {
+			signature.WriteByte('*')
+		}
+		signature.WriteByte(',')
+	}
+	if archetype, ok := this.signatures[signature.String()]; ok {
+		return archetype
+	}
+
+	archetype := newArchetype(len(this.archetypes), this.ecs.registry, types, ids)
+	this.archetypes = append(this.archetypes, archetype)
+	this.signatures[signature.String()] = archetype
+	return archetype
+}
+
+// GetEntityComponent is a typed helper to get a cast entity component from the ECS, fails if missing (see TryGetEntityComponent)
 func GetEntityComponent[T any](ecs *ECS, eId uint64) T {
-	vals := ecs.GetComponents(reflect.TypeFor[T]())
-	return vals[eId].(T)
+	c, err := TryGetEntityComponent[T](ecs, eId)
+	if err != nil {
+		ecs.fail(err)
+	}
+	return c
 }
 
-// GetComponentFor is a casting helper to return a typed component by entity id
+// TryGetEntityComponent returns the entity component of type T (pointer or value form),
+// ErrNoSuchEntity for unknown entities and ErrNoComponent if the entity lacks T
+func TryGetEntityComponent[T any](ecs *ECS, eId uint64) (T, error) {
+	var zero T
+	if _, ok := ecs.entities[eId]; !ok {
+		return zero, fmt.Errorf("%w: %d", ErrNoSuchEntity, eId)
+	}
+	c, ok := ecs.components.GetComponent(eId, reflect.TypeFor[T]())
+	if !ok {
+		return zero, fmt.Errorf("%w: %v of entity %d", ErrNoComponent, reflect.TypeFor[T](), eId)
+	}
+	// Read from the column, pointers to values point into it as in queries
+	loc := ecs.components.locations[eId]
+	if col := loc.archetype.column(reflect.TypeFor[T]()); col != nil {
+		return castComponent[T](col, loc.row), nil
+	}
+	// Tags have no column, only a zero value
+	return convertComponent[T](c), nil
+}
+
+// GetComponentFor is a casting helper to return a typed component by entity id, panics if missing (see TryGetComponentFor)
 func GetComponentFor[T any](components map[uint64]any, eId uint64) T {
-	return components[eId].(T)
+	c, err := TryGetComponentFor[T](components, eId)
+	if err != nil {
+		panic(err)
+	}
+	return c
 }
 
-// GetComponentsFor creates a typed map of the components
+// TryGetComponentFor returns the typed component of the entity, ErrNoComponent if missing
+func TryGetComponentFor[T any](components map[uint64]any, eId uint64) (T, error) {
+	c, ok := components[eId]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %v of entity %d", ErrNoComponent, reflect.TypeFor[T](), eId)
+	}
+	return tryConvert[T](c)
+}
+
+// GetComponentsFor creates a typed map of the components (see TryGetComponentsFor)
 func GetComponentsFor[T any](ecs *ECS) map[uint64]T {
-	components := ecs.GetComponents(reflect.TypeFor[T]())
-	typedComponents := make(map[uint64]T, len(components))
-	for i, c := range components {
-		typedComponents[i] = c.(T)
+	typedComponents, err := TryGetComponentsFor[T](ecs)
+	if err != nil {
+		ecs.fail(err)
 	}
 	return typedComponents
 }
+
+// TryGetComponentsFor creates a typed map of the components, empty if T is not registered.
+// Pointer and value forms are converted, so the error is nil for every T.
+func TryGetComponentsFor[T any](ecs *ECS) (map[uint64]T, error) {
+	cType := reflect.TypeFor[T]()
+	typedComponents := make(map[uint64]T)
+	id, ok := ecs.registry.Lookup(cType)
+	if !ok {
+		return typedComponents, nil
+	}
+	for _, a := range ecs.components.archetypes {
+		if !a.HasId(id) {
+			continue
+		}
+		c := a.columnById(id)
+		if c == nil {
+			// Tags have no column
+			for _, eId := range a.entities {
+				typedComponents[eId] = zeroComponent(cType).(T)
+			}
+			continue
+		}
+		// Exact column type can be read densely, otherwise convert one by one
+		if c.typ == cType {
+			for row, component := range columnData[T](c) {
+				typedComponents[a.entities[row]] = component
+			}
+		} else {
+			for row, eId := range a.entities {
+				typedComponents[eId] = castComponent[T](c, row)
+			}
+		}
+	}
+	return typedComponents, nil
+}
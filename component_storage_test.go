@@ -0,0 +1,60 @@
+package ecs
+
+import (
+	"testing"
+)
+
+func Test_Archetypes(t *testing.T) {
+	ecs := New()
+
+	// Same component set should share one archetype
+	player1 := createPlayer("player1")
+	ecs.CreateEntity(&player1.PositionComponent, &player1.VelocityComponent)
+	player2 := createPlayer("player2")
+	ecs.CreateEntity(&player2.VelocityComponent, &player2.PositionComponent)
+	player3 := createPlayer("player3")
+	ecs.CreateEntity(&player3.PositionComponent, &player3.BoundsComponent)
+
+	// Assertions
+	if len(ecs.components.Archetypes()) != 2 {
+		t.Errorf("archetypes = %d; expected %d", len(ecs.components.Archetypes()), 2)
+	}
+	if ecs.components.Archetypes()[0].Len() != 2 {
+		t.Errorf("archetype[0] = %d; expected %d", ecs.components.Archetypes()[0].Len(), 2)
+	}
+}
+
+func Test_Archetypes_Move(t *testing.T) {
+	ecs := New()
+
+	player1 := createPlayer("player1")
+	e1 := ecs.CreateEntity(&player1.PositionComponent, &player1.VelocityComponent)
+	player2 := createPlayer("player2")
+	e2 := ecs.CreateEntity(&player2.PositionComponent, &player2.VelocityComponent)
+
+	// Remove one component of the first entity, the second one should be swapped into its row
+	ecs.components.RemoveComponent(e1, &player1.VelocityComponent)
+
+	// Assertions
+	if c, ok := ecs.components.GetComponent(e1.Id(), VelocityComponent{}); ok {
+		t.Errorf("velocity = %v; expected none", c)
+	}
+	if c, _ := ecs.components.GetComponent(e1.Id(), PositionComponent{}); c != &player1.PositionComponent {
+		t.Errorf("position = %v; expected %v", c, &player1.PositionComponent)
+	}
+	if c, _ := ecs.components.GetComponent(e2.Id(), VelocityComponent{}); c != &player2.VelocityComponent {
+		t.Errorf("velocity = %v; expected %v", c, &player2.VelocityComponent)
+	}
+	if len(ecs.GetComponents(VelocityComponent{})) != 1 {
+		t.Errorf("velocities = %d; expected %d", len(ecs.GetComponents(VelocityComponent{})), 1)
+	}
+	if len(ecs.GetComponents(PositionComponent{})) != 2 {
+		t.Errorf("positions = %d; expected %d", len(ecs.GetComponents(PositionComponent{})), 2)
+	}
+
+	// Remove all, the entity should be gone from storage
+	ecs.RemoveEntityNow(e2.Id())
+	if _, ok := ecs.components.GetComponent(e2.Id(), PositionComponent{}); ok {
+		t.Errorf("entity %d still stored", e2.Id())
+	}
+}
@@ -0,0 +1,48 @@
+package ecs
+
+// RunConditioner may be implemented by systems to decide on every update whether to run
+type RunConditioner interface {
+	ShouldRun(ecs *ECS) bool
+}
+
+// shouldRun checks whether the given system is enabled and its run conditions are met
+func (this *ECS) shouldRun(s System) bool {
+	if this.disabled[s] {
+		return false
+	}
+	for _, condition := range this.conditions[s] {
+		if !condition(this) {
+			return false
+		}
+	}
+	if conditioner, ok := s.(RunConditioner); ok {
+		return conditioner.ShouldRun(this)
+	}
+	return true
+}
+
+// runnable returns the systems of the group to run on this update, the group itself if all of them run
+func (this *ECS) runnable(systems []System, timestep timestep) []System {
+	var filtered []System
+	for i, s := range systems {
+		if timestep.includes(s) && this.shouldRun(s) {
+			if filtered != nil {
+				filtered = append(filtered, s)
+			}
+		} else if filtered == nil {
+			filtered = append(make([]System, 0, len(systems)), systems[:i]...)
+		}
+	}
+	if filtered == nil {
+		return systems
+	}
+	return filtered
+}
+
+// InState returns a run condition which is met while the resource of type S equals the given state
+func InState[S comparable](state S) func(ecs *ECS) bool {
+	return func(ecs *ECS) bool {
+		current, ok := Resource[S](ecs)
+		return ok && current == state
+	}
+}
@@ -0,0 +1,70 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+)
+
+type GameState int
+
+const (
+	GameStateMenu GameState = iota
+	GameStatePlaying
+)
+
+func Test_SystemEnabled(t *testing.T) {
+	for _, ecs := range []*ECS{New(), NewParallel()} {
+		moveSystem := MoveSystem{}
+		ecs.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
+		stepSystem := StepSystem{}
+		ecs.AddSystem(&stepSystem)
+
+		player := createPlayer("player")
+		ecs.SetSystemEnabled(&moveSystem, false)
+		ecs.CreateEntity(&player.PositionComponent, &player.VelocityComponent)
+		ecs.Update(33 * time.Millisecond)
+
+		// Assertions
+		if ecs.SystemEnabled(&moveSystem) || player.X != 1 || stepSystem.runs != 1 {
+			t.Errorf("position = %d; expected %d while disabled", player.X, 1)
+		}
+		if len(moveSystem.Entities()) != 1 {
+			t.Errorf("entities = %d; expected %d, still tracked", len(moveSystem.Entities()), 1)
+		}
+		ecs.SetSystemEnabled(&moveSystem, true)
+		ecs.Update(33 * time.Millisecond)
+		if player.X != 1+player.DX {
+			t.Errorf("position = %d; expected %d", player.X, 1+player.DX)
+		}
+		ecs.Close()
+	}
+}
+
+func Test_RunConditions(t *testing.T) {
+	ecs := New()
+	InsertResource(ecs, GameStateMenu)
+
+	playSystem := StepSystem{}
+	playSystem.RunIf(InState(GameStatePlaying))
+	everySystem := StepSystem{}
+	everySystem.RunEvery(3)
+	ecs.AddSystem(&playSystem)
+	ecs.AddSystem(&everySystem)
+
+	for range 4 {
+		ecs.Update(33 * time.Millisecond)
+	}
+
+	// Assertions
+	if playSystem.runs != 0 {
+		t.Errorf("runs = %d; expected %d in the menu", playSystem.runs, 0)
+	}
+	if everySystem.runs != 2 {
+		t.Errorf("runs = %d; expected %d", everySystem.runs, 2)
+	}
+	InsertResource(ecs, GameStatePlaying)
+	ecs.Update(33 * time.Millisecond)
+	if playSystem.runs != 1 {
+		t.Errorf("runs = %d; expected %d while playing", playSystem.runs, 1)
+	}
+}
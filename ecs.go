@@ -1,51 +1,147 @@
 package ecs
 
 import (
+	"fmt"
 	"reflect"
 	"sync"
-	"sync/atomic"
 	"time"
 )
 
 type ECS struct {
 	parallel bool
+	// the world tick for change detection, advanced around every system (group) run during Update
+	tick uint64
 
-	// unique atomic counter per ECS
-	entityCounter atomic.Uint64
+	// unique, recycled entity handles per ECS
+	entityIds entityAllocator
 
-	entities   map[uint64]Entity
+	entities   map[uint64]*BaseEntity
 	toRemove   []uint64
 	systems    *SystemStorage
 	components *ComponentStorage
 	context    map[reflect.Type]any
+	registry   *ComponentRegistry
+	// context types added by value, stored by pointer to be changed in place
+	contextValues map[reflect.Type]bool
+	// per system, a command buffer played back at the sync points of Update
+	commands map[System]*CommandBuffer
+	// per type, double-buffered events
+	events      map[reflect.Type]eventBuffer
+	eventsMutex sync.RWMutex
+	// per component id, lifecycle hooks and all observers of component sets
+	hooks     map[ComponentID]*componentHooks
+	observers []*Observer
+	// the entity hierarchy, per child its parent and per parent its ordered children
+	parents  map[uint64]uint64
+	children map[uint64][]uint64
+	// per relation id, all pairs between entities
+	relations map[ComponentID]*relation
+	// named entity templates
+	prefabs map[string]*Prefab
+	// systems skipped by Update, still tracking their entities, and further run conditions per system
+	disabled   map[System]bool
+	conditions map[System][]func(ecs *ECS) bool
+	// all state machines in order of addition, and by state type
+	states     []stateTransitioner
+	stateTypes map[reflect.Type]stateTransitioner
+	// reports misuse instead of panicking, if set
+	errorHandler func(err error)
+	// the inbox of changes from other goroutines, if attached
+	syncWorld *SyncWorld
+	// the worker pool size of data-parallel iteration and its command buffers, played back at the next sync point
+	workers       int
+	deferred      []*CommandBuffer
+	deferredMutex sync.Mutex
+	// the long-lived worker pool, started on first use, and the job running the systems of a parallel group
+	workerPool *workerPool
+	poolMutex  sync.Mutex
+	groupJob   *job
+	group      []System
+	groupDt    time.Duration
+	// the failures of the running update and how to handle them, with the failure count per system
+	failures      []error
+	checked       int
+	failuresMutex sync.Mutex
+	failurePolicy FailurePolicy
+	maxFailures   int
+	failureCounts map[System]int
 }
 
-func newECS(parallel bool) (this *ECS) {
+func newECS(parallel bool, options ...Option) (this *ECS) {
 	this = new(ECS)
 
 	this.parallel = parallel
-	this.entities = make(map[uint64]Entity)
+	this.tick = 1
+	this.entities = make(map[uint64]*BaseEntity)
 	this.systems = NewSystemStorage(this, parallel)
 	this.components = NewComponentStorage(this)
 	this.context = make(map[reflect.Type]any)
+	this.contextValues = make(map[reflect.Type]bool)
+	this.registry = NewComponentRegistry()
+	this.commands = make(map[System]*CommandBuffer)
+	this.events = make(map[reflect.Type]eventBuffer)
+	this.hooks = make(map[ComponentID]*componentHooks)
+	this.parents = make(map[uint64]uint64)
+	this.children = make(map[uint64][]uint64)
+	this.relations = make(map[ComponentID]*relation)
+	this.prefabs = make(map[string]*Prefab)
+	this.disabled = make(map[System]bool)
+	this.conditions = make(map[System][]func(ecs *ECS) bool)
+	this.stateTypes = make(map[reflect.Type]stateTransitioner)
+	this.workers = defaultWorkers()
+	this.groupJob = newJob(this.runGroupSystem)
+	this.maxFailures = 3
+	this.failureCounts = make(map[System]int)
+
+	for _, option := range options {
+		option(this)
+	}
 
 	return this
 }
 
+// Option configures a world on creation
+type Option func(ecs *ECS)
+
+// WithErrorHandler reports misuse (e.g. missing components of GetEntityComponent, cycles of AddSystem or unknown states)
+// to the handler instead of panicking. The failing call returns a zero value.
+func WithErrorHandler(handler func(err error)) Option {
+	return func(ecs *ECS) {
+		ecs.errorHandler = handler
+	}
+}
+
 // New creates a new synchronous ecs world
-func New() (this *ECS) {
-	return newECS(false)
+func New(options ...Option) (this *ECS) {
+	return newECS(false, options...)
 }
 
 // NewParallel creates a new asynchronous ecs world (costs, do not use if you don't need it)
-func NewParallel() (this *ECS) {
-	return newECS(true)
+func NewParallel(options ...Option) (this *ECS) {
+	return newECS(true, options...)
 }
 
-// Clear nils all entities from this world
+// Clear nils all entities from this world and stops its worker pool
 func (this *ECS) Clear() {
+	this.Close()
 	this.entities = nil
+	this.entityIds = entityAllocator{}
 	this.context = nil
+	this.contextValues = nil
+	this.commands = nil
+	this.events = nil
+	this.hooks = nil
+	this.observers = nil
+	this.parents = nil
+	this.children = nil
+	this.relations = nil
+	this.prefabs = nil
+	this.disabled = nil
+	this.conditions = nil
+	this.states = nil
+	this.stateTypes = nil
+	this.syncWorld = nil
+	this.failureCounts = nil
 	if this.systems != nil {
 		this.systems.Clear()
 	}
@@ -54,27 +150,62 @@ func (this *ECS) Clear() {
 	}
 }
 
-// AddContext attaches any service, map or other interfaces to this ECS (there can only be one per type)
+// Registry returns the registry of named component and context types
+func (this *ECS) Registry() *ComponentRegistry {
+	return this.registry
+}
+
+// AddContext attaches any service, map or other interfaces to this ECS (there can only be one per type), see InsertResource
 func (this *ECS) AddContext(c any) *ECS {
-	this.context[this.getPlainType(c)] = c
+	typ := this.getPlainType(c)
+	if value := reflect.ValueOf(c); value.Kind() != reflect.Pointer {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		this.context[typ] = ptr.Interface()
+		this.contextValues[typ] = true
+		return this
+	}
+	this.context[typ] = c
+	delete(this.contextValues, typ)
 	return this
 }
 
-// GetContext returns a context from the ECS (there can only be one per type)
+// GetContext returns a context from the ECS (there can only be one per type), in the form it got added
 func (this *ECS) GetContext(c any) any {
-	return this.context[this.getPlainType(c)]
+	typ := this.getPlainType(c)
+	if this.contextValues[typ] {
+		return reflect.ValueOf(this.context[typ]).Elem().Interface()
+	}
+	return this.context[typ]
 }
 
-// GetContextFor is a convenience generic call for easier type
+// GetContextFor is a convenience generic call for easier type, fails if missing (see TryGetContextFor)
 func GetContextFor[T any](ecs *ECS) T {
-	v := ecs.GetContext(reflect.TypeFor[T]())
-	return v.(T)
+	v, err := TryGetContextFor[T](ecs)
+	if err != nil {
+		ecs.fail(err)
+	}
+	return v
+}
+
+// TryGetContextFor returns the context of type T (pointer or value form) or ErrNoContext
+func TryGetContextFor[T any](ecs *ECS) (T, error) {
+	// Stored by pointer, to return the live context for pointer forms
+	v := ecs.context[plainType(reflect.TypeFor[T]())]
+	if v == nil {
+		var zero T
+		return zero, fmt.Errorf("%w: %v", ErrNoContext, reflect.TypeFor[T]())
+	}
+	return tryConvert[T](v)
 }
 
 // CreateEntity scaffolds a new entity with the given components
 func (this *ECS) CreateEntity(components ...any) Entity {
-	entity := NewEntity(&this.entityCounter)
+	return this.createEntity(newEntity(this.entityIds.allocate()), components...)
+}
 
+// createEntity registers the given, new entity with the given components
+func (this *ECS) createEntity(entity *BaseEntity, components ...any) Entity {
 	// Store entities
 	this.entities[entity.Id()] = entity
 	// Add components to entity as reference
@@ -92,12 +223,27 @@ func (this *ECS) CreateEntity(components ...any) Entity {
 	return entity
 }
 
-// AddEntity via reflection of embedded structs
+// AddEntity via reflection of embedded structs, fails if e is no struct (see TryAddEntity)
 func (this *ECS) AddEntity(e any) Entity {
+	entity, err := this.TryAddEntity(e)
+	if err != nil {
+		this.fail(err)
+	}
+	return entity
+}
+
+// TryAddEntity creates an entity of the struct fields of e (or the struct e points to), ErrTypeMismatch if e is no struct
+func (this *ECS) TryAddEntity(e any) (Entity, error) {
 	// Get structs as components
 	var components []any
 	v := reflect.ValueOf(e)
-	t := reflect.TypeOf(e)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected a struct, got %T", ErrTypeMismatch, e)
+	}
+	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
 		val := v.Field(i)
 
@@ -118,10 +264,10 @@ func (this *ECS) AddEntity(e any) Entity {
 		}
 	}
 
-	return this.CreateEntity(components...)
+	return this.CreateEntity(components...), nil
 }
 
-// RemoveEntity marks an entity for deletion in the next iteration, to not affect the current run
+// RemoveEntity marks an entity (and its descendants) for deletion in the next iteration, to not affect the current run
 func (this *ECS) RemoveEntity(id uint64) {
 	this.toRemove = append(this.toRemove, id)
 }
@@ -151,25 +297,128 @@ func (this *ECS) removeEntities() {
 	this.toRemove = make([]uint64, 0)
 }
 
-// RemoveEntityNow detaches the entity now, no matter if more systems are running
+// RemoveEntityNow detaches the entity and all its descendants now, no matter if more systems are running
 func (this *ECS) RemoveEntityNow(id uint64) {
+	if this.entities[id] != nil {
+		// Check the whole cascade up front, to not leave a half removed hierarchy behind
+		this.checkRemoval(id)
+		this.removeEntity(id)
+	}
+}
+
+// removeEntity removes the entity, the pairs pointing at it and all entities its removal cascades into
+func (this *ECS) removeEntity(id uint64) {
 	entity := this.entities[id]
 
 	if entity != nil {
+		// Clean up the pairs pointing at it and cascade to the children first
+		this.removeRelations(id)
+		this.removeHierarchy(id)
+		// The cascade may have removed this entity already
+		if this.entities[id] != entity {
+			return
+		}
+
 		// Detach from systems
 		this.DetachEntityFromNow(id)
 
 		// Remove from global components
 		this.components.RemoveComponent(entity, entity.GetComponents()...)
 
-		// Delete entity
+		// Delete entity and recycle its index
 		delete(this.entities, id)
+		this.entityIds.release(EntityID(id))
 	}
 }
 
 // GetEntity returns the id referenced entity of this ECS
 func (this *ECS) GetEntity(id uint64) Entity {
-	return this.entities[id]
+	if entity, ok := this.entities[id]; ok {
+		return entity
+	}
+	return nil
+}
+
+// IsAlive checks whether the handle references an existing entity, stale handles of removed entities are rejected
+func (this *ECS) IsAlive(id EntityID) bool {
+	_, ok := this.entities[uint64(id)]
+	return ok
+}
+
+// AddComponents attaches the given components to a live entity and re-matches it against all systems
+func (this *ECS) AddComponents(id uint64, components ...any) {
+	entity := this.entities[id]
+	if entity == nil {
+		return
+	}
+
+	before := this.systems.QuerySystems(entity.GetComponents()...)
+	for _, c := range components {
+		entity.SetComponent(c)
+	}
+	this.components.AddComponent(entity, components...)
+	this.rematchEntity(entity, before)
+}
+
+// RemoveComponents detaches the given components (or types) from a live entity and re-matches it against all systems
+func (this *ECS) RemoveComponents(id uint64, components ...any) {
+	entity := this.entities[id]
+	if entity == nil {
+		return
+	}
+
+	before := this.systems.QuerySystems(entity.GetComponents()...)
+	for _, c := range components {
+		entity.RemoveComponent(c)
+	}
+	this.components.RemoveComponent(entity, components...)
+	this.rematchEntity(entity, before)
+}
+
+// AddTag attaches the given tag to a live entity, moving it between archetypes without copying any component through interfaces
+func (this *ECS) AddTag(id uint64, tag any) {
+	this.toggleTag(id, tag, true)
+}
+
+// RemoveTag detaches the given tag (or type) from a live entity
+func (this *ECS) RemoveTag(id uint64, tag any) {
+	this.toggleTag(id, tag, false)
+}
+
+// toggleTag adds or removes the tag, falling back to AddComponents/RemoveComponents for components and lifecycle listeners
+func (this *ECS) toggleTag(id uint64, tag any, on bool) {
+	entity := this.entities[id]
+	if entity == nil {
+		return
+	}
+	if !this.registry.isTag(this.registry.Id(tag)) || this.hasLifecycleListeners() {
+		if on {
+			this.AddComponents(id, tag)
+		} else {
+			this.RemoveComponents(id, tag)
+		}
+		return
+	}
+
+	before := this.systems.QuerySystems(entity.GetComponents()...)
+	if on {
+		entity.SetComponent(tag)
+	} else {
+		entity.RemoveComponent(tag)
+	}
+	this.components.toggleTag(id, tag, on)
+	this.rematchEntity(entity, before)
+}
+
+// rematchEntity attaches the entity to newly matching systems and detaches it from no longer matching ones
+func (this *ECS) rematchEntity(entity Entity, before []System) {
+	after := this.systems.QuerySystems(entity.GetComponents()...)
+	for _, system := range this.systems.differenceSystems(after, before) {
+		system.AttachEntity(entity)
+	}
+	for _, system := range this.systems.differenceSystems(before, after) {
+		system.DetachEntity(entity)
+	}
 }
 
 // GetComponents by given type
@@ -177,9 +426,22 @@ func (this *ECS) GetComponents(componentType any) map[uint64]interface{} {
 	return this.components.GetComponents(componentType)
 }
 
-// AddSystem attaches the given system to this ECS under the given types
+// AddSystem attaches the given system to this ECS under the given types or queries.
+// Fails with ErrCycle if the declared system order contains a cycle, the system is not kept then.
 func (this *ECS) AddSystem(s System, types ...any) *ECS {
-	systemTypes := this.systems.AddSystem(s, types...)
+	this.systems.AddSystem(s, types...)
+	this.attachSystem(s)
+	return this
+}
+
+// attachSystem sets up the stored system and attaches all matching existing entities, false if it was not kept
+func (this *ECS) attachSystem(s System) bool {
+	if err := this.systems.Err(); err != nil {
+		this.systems.RemoveSystem(s)
+		this.fail(err)
+		return false
+	}
+	this.commands[s] = NewCommandBuffer(this)
 
 	// Check whether existing entities should be added to this new system
 	for _, entity := range this.entities {
@@ -189,59 +451,183 @@ func (this *ECS) AddSystem(s System, types ...any) *ECS {
 			entityTypes = append(entityTypes, reflect.TypeOf(c))
 		}
 
-		if this.systems.testTypesSubset(systemTypes, entityTypes) {
+		if this.systems.testSystemMatch(s, entityTypes) {
 			s.AttachEntity(entity)
 		}
 	}
 
-	return this
+	return true
 }
 
 // RemoveSystem deletes the given system from this ECS
 func (this *ECS) RemoveSystem(s System) *ECS {
 	this.systems.RemoveSystem(s)
+	delete(this.commands, s)
+	delete(this.disabled, s)
+	delete(this.conditions, s)
+	delete(this.failureCounts, s)
 	return this
 }
 
-// Update calls all systems to run and do their stuff
-func (this *ECS) Update(dt time.Duration) *ECS {
+// SetSystemEnabled toggles whether Update runs the given system, a disabled system keeps tracking its entities
+func (this *ECS) SetSystemEnabled(s System, enabled bool) {
+	if enabled {
+		delete(this.disabled, s)
+	} else {
+		this.disabled[s] = true
+	}
+}
+
+// SystemEnabled returns whether Update runs the given system
+func (this *ECS) SystemEnabled(s System) bool {
+	return !this.disabled[s]
+}
+
+// AllParallel returns the systems grouped into concurrently runnable groups (parallel worlds only)
+func (this *ECS) AllParallel() [][]System {
+	return this.systems.AllParallel()
+}
+
+// Commands returns the command buffer of the given system, to defer structural changes to the next sync point
+func (this *ECS) Commands(s System) *CommandBuffer {
+	return this.commands[s]
+}
+
+// Update calls all systems to run and do their stuff, returns the joined failures (SystemError) of this update
+func (this *ECS) Update(dt time.Duration) error {
+	return this.update(dt, timestepAny)
+}
+
+// update runs one frame with all systems of the given timestep
+func (this *ECS) update(dt time.Duration, timestep timestep) error {
+	this.lockFrame()
+	defer this.unlockFrame()
+
+	if this.beginFrame(dt) {
+		this.runSystems(dt, timestep)
+	}
+	return this.collectFailures()
+}
+
+// lockFrame excludes concurrent readers for a frame and applies the changes queued by other goroutines
+func (this *ECS) lockFrame() {
+	if this.syncWorld != nil {
+		this.syncWorld.mutex.Lock()
+		this.syncWorld.drain()
+	}
+}
+
+// unlockFrame lets concurrent readers in again after a frame
+func (this *ECS) unlockFrame() {
+	if this.syncWorld != nil {
+		this.syncWorld.mutex.Unlock()
+	}
+}
+
+// beginFrame applies the state transitions, entity removals and events once per frame, false if the frame is aborted
+func (this *ECS) beginFrame(dt time.Duration) bool {
+	// Apply the state transitions, marking the entities of exited states for removal
+	this.applyStates(dt)
+	if this.applyFailures() {
+		return false
+	}
 	// Clear all marked entities
 	this.removeEntities()
+	// Make the last frame's events readable
+	this.swapEvents()
+	// Advance the world tick, so changes made in between updates are detected
+	this.tick++
+	return true
+}
 
-	// Iterate on the systems
+// runSystems runs all systems of the given timestep, false if the frame is aborted
+func (this *ECS) runSystems(dt time.Duration, timestep timestep) bool {
 	if this.parallel {
 		systems := this.systems.AllParallel()
 		for _, s := range systems {
-			// Wait for all systems in a parallel group to finish
-			var wg sync.WaitGroup
-			for _, system := range s {
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-					system.Run(this, dt)
-				}()
+			if s = this.runnable(s, timestep); len(s) == 0 {
+				continue
+			}
+			this.tick++
+
+			// Run all systems of a parallel group on the pool and wait for them to finish
+			this.group, this.groupDt = s, dt
+			this.pool().run(this.groupJob, len(s))
+			this.group = nil
+
+			// Sync point after every group
+			this.tick++
+			this.playbackCommands(s...)
+			if this.applyFailures() {
+				return false
 			}
-			wg.Wait()
 		}
 
 	} else {
 		systems := this.systems.All()
 		for _, s := range systems {
-			s.Run(this, dt)
+			if !timestep.includes(s) || !this.shouldRun(s) {
+				continue
+			}
+			this.runSystem(s, dt)
+			if this.applyFailures() {
+				return false
+			}
 		}
 	}
+	return true
+}
 
-	return this
+// runGroupSystem runs the i-th system of the current parallel group
+func (this *ECS) runGroupSystem(worker int, i int) {
+	this.run(this.group[i], this.groupDt)
+}
+
+// runSystem runs a single system, followed by its sync point
+func (this *ECS) runSystem(s System, dt time.Duration) {
+	this.tick++
+	this.run(s, dt)
+
+	// Sync point after every system
+	this.tick++
+	this.playbackCommands(s)
+}
+
+// Tick returns the current world tick of change detection
+func (this *ECS) Tick() uint64 {
+	return this.tick
+}
+
+// MarkChanged flags the entity's component of the given type as changed now, e.g. after mutating it outside a query
+func (this *ECS) MarkChanged(id uint64, componentType any) {
+	this.components.MarkChanged(id, componentType)
+}
+
+// playbackCommands applies the recorded commands of the given systems in their order
+func (this *ECS) playbackCommands(systems ...System) {
+	for _, system := range systems {
+		if buffer := this.commands[system]; buffer != nil && buffer.Len() > 0 {
+			buffer.Playback()
+		}
+	}
+	this.playbackDeferred()
 }
 
 // getPlainType returns a non-pointer type from any given
 func (this *ECS) getPlainType(t any) reflect.Type {
-	var typ reflect.Type
-	if _, ok := t.(reflect.Type); !ok {
-		typ = reflect.TypeOf(t)
-	} else {
-		typ = t.(reflect.Type)
+	return plainTypeOf(t)
+}
+
+// plainTypeOf returns a non-pointer type from any given value or reflect.Type
+func plainTypeOf(t any) reflect.Type {
+	if typ, ok := t.(reflect.Type); ok {
+		return plainType(typ)
 	}
+	return plainType(reflect.TypeOf(t))
+}
+
+// plainType strips one pointer level from the given type
+func plainType(typ reflect.Type) reflect.Type {
 	if typ.Kind() == reflect.Pointer {
 		typ = typ.Elem()
 	}
@@ -2,6 +2,7 @@ package ecs
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -170,6 +171,7 @@ func Test_ECS(t *testing.T) {
 func Test_ECS_Parallel(t *testing.T) {
 	// Create a new world
 	ecs := NewParallel()
+	defer ecs.Close()
 
 	// Add some interacting systems, working with same and different components
 	ecs.AddSystem(&MoveSystem{}, &PositionComponent{}, &VelocityComponent{})
@@ -449,3 +451,69 @@ func Benchmark_ECS_Parallel(b *testing.B) {
 		ecs.Update(33 * time.Millisecond)
 	}
 }
+
+func Test_ECS_AddRemoveComponents(t *testing.T) {
+	// Create a new world
+	ecs := New()
+
+	// Add a system which needs a component the entity does not have yet
+	moveSystem := MoveSystem{}
+	ecs.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
+
+	player := createPlayer("player")
+	entity := ecs.CreateEntity(&player.PositionComponent)
+
+	ecs.Update(33 * time.Millisecond)
+	// Assertions
+	if len(moveSystem.Entities()) != 0 || player.X != 1 {
+		t.Errorf("player(%d, %d); expected %d", player.X, player.Y, 1)
+	}
+
+	// Add the missing component at runtime
+	ecs.AddComponents(entity.Id(), &player.VelocityComponent)
+
+	ecs.Update(33 * time.Millisecond)
+	// Assertions
+	if len(moveSystem.Entities()) != 1 || player.X != (1+player.DX) {
+		t.Errorf("player(%d, %d); expected %d", player.X, player.Y, 1+player.DX)
+	}
+
+	// Remove it again by type
+	ecs.RemoveComponents(entity.Id(), reflect.TypeFor[VelocityComponent]())
+
+	ecs.Update(33 * time.Millisecond)
+	// Assertions
+	if len(moveSystem.Entities()) != 0 || player.X != (1+player.DX) {
+		t.Errorf("player(%d, %d); expected %d", player.X, player.Y, 1+player.DX)
+	}
+	if len(entity.GetComponents()) != 1 {
+		t.Errorf("components = %d; expected %d", len(entity.GetComponents()), 1)
+	}
+}
+
+func Test_ECS_RecycleEntity(t *testing.T) {
+	// Create a new world
+	ecs := New()
+
+	player := createPlayer("player")
+	entity := ecs.CreateEntity(&player.PositionComponent)
+	handle := entity.(*BaseEntity).Handle()
+
+	// Remove and recreate, the index is reused with a new generation
+	ecs.RemoveEntityNow(entity.Id())
+	recreated := ecs.CreateEntity(&player.PositionComponent).(*BaseEntity).Handle()
+
+	// Assertions
+	if recreated.Index() != handle.Index() || recreated.Generation() != handle.Generation()+1 {
+		t.Errorf("handle(%d, %d); expected (%d, %d)", recreated.Index(), recreated.Generation(), handle.Index(), handle.Generation()+1)
+	}
+	if ecs.IsAlive(handle) {
+		t.Errorf("handle %d; expected to be stale", handle)
+	}
+	if !ecs.IsAlive(recreated) {
+		t.Errorf("handle %d; expected to be alive", recreated)
+	}
+	if ecs.GetEntity(uint64(handle)) != nil {
+		t.Errorf("entity %d; expected to be removed", handle)
+	}
+}
@@ -2,9 +2,68 @@ package ecs
 
 import (
 	"encoding/json"
+	"reflect"
+	"slices"
+	"sync"
 	"sync/atomic"
 )
 
+// EntityID is an entity handle made of a recycled index (low 32 bits) and its generation (high 32 bits)
+type EntityID uint64
+
+// NewEntityID packs the given index and generation into a handle
+func NewEntityID(index, generation uint32) EntityID {
+	return EntityID(uint64(generation)<<32 | uint64(index))
+}
+
+// Index returns the recycled index of this handle
+func (this EntityID) Index() uint32 {
+	return uint32(this)
+}
+
+// Generation returns how often the index of this handle has been recycled
+func (this EntityID) Generation() uint32 {
+	return uint32(this >> 32)
+}
+
+// entityAllocator hands out entity handles, recycling the indices of removed entities with a new generation
+type entityAllocator struct {
+	mutex sync.Mutex
+	// per index the current generation, index 0 is never used
+	generations []uint32
+	free        []uint32
+}
+
+// allocate returns a new handle, safe to be called concurrently
+func (this *entityAllocator) allocate() EntityID {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if len(this.generations) == 0 {
+		this.generations = append(this.generations, 0)
+	}
+	if n := len(this.free); n > 0 {
+		index := this.free[n-1]
+		this.free = this.free[:n-1]
+		return NewEntityID(index, this.generations[index])
+	}
+	this.generations = append(this.generations, 0)
+	return NewEntityID(uint32(len(this.generations)-1), 0)
+}
+
+// release bumps the generation of the handle's index and frees it for reuse
+func (this *entityAllocator) release(id EntityID) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	index := id.Index()
+	if int(index) >= len(this.generations) || this.generations[index] != id.Generation() {
+		return
+	}
+	this.generations[index]++
+	this.free = append(this.free, index)
+}
+
 type Entity interface {
 	Id() uint64
 	GetComponents() []any
@@ -22,9 +81,14 @@ func (this *BaseEntity) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// NewEntity creates an entity from a plain counter, worlds use recycled handles instead
 func NewEntity(counter *atomic.Uint64) (this *BaseEntity) {
+	return newEntity(EntityID(counter.Add(1)))
+}
+
+func newEntity(id EntityID) (this *BaseEntity) {
 	this = new(BaseEntity)
-	this.id = counter.Add(1)
+	this.id = uint64(id)
 	return this
 }
 
@@ -38,6 +102,26 @@ func (this *BaseEntity) AddComponents(component ...any) {
 	}
 }
 
+// SetComponent replaces the component of the same (plain) type or adds it
+func (this *BaseEntity) SetComponent(component any) {
+	cType := plainType(reflect.TypeOf(component))
+	for i, c := range this.components {
+		if plainType(reflect.TypeOf(c)) == cType {
+			this.components[i] = component
+			return
+		}
+	}
+	this.AddComponent(component)
+}
+
+// RemoveComponent deletes the component of the same (plain) type
+func (this *BaseEntity) RemoveComponent(component any) {
+	cType := plainTypeOf(component)
+	this.components = slices.DeleteFunc(this.components, func(c any) bool {
+		return plainType(reflect.TypeOf(c)) == cType
+	})
+}
+
 func (this *BaseEntity) GetComponents() []any {
 	return this.components
 }
@@ -45,3 +129,8 @@ func (this *BaseEntity) GetComponents() []any {
 func (this *BaseEntity) Id() uint64 {
 	return this.id
 }
+
+// Handle returns the id as index and generation handle
+func (this *BaseEntity) Handle() EntityID {
+	return EntityID(this.id)
+}
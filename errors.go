@@ -0,0 +1,45 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrNoSuchEntity is returned for ids of unknown or removed entities
+	ErrNoSuchEntity = errors.New("ecs: no such entity")
+	// ErrNoComponent is returned if an entity lacks the requested component
+	ErrNoComponent = errors.New("ecs: no such component")
+	// ErrNoContext is returned if no context (or resource) of the requested type exists
+	ErrNoContext = errors.New("ecs: no such context")
+	// ErrTypeMismatch is returned if a value cannot be converted to the requested type
+	ErrTypeMismatch = errors.New("ecs: type mismatch")
+	// ErrNoState is returned if no state machine of the requested type was added
+	ErrNoState = errors.New("ecs: no such state")
+	// ErrStoredComponent is returned if a type is declared a tag after being stored as component
+	ErrStoredComponent = errors.New("ecs: already stored as component")
+)
+
+// fail reports the error to the error handler of this world, panics without one
+func (this *ECS) fail(err error) {
+	if this.errorHandler == nil {
+		panic(err)
+	}
+	this.errorHandler(err)
+}
+
+// tryConvert casts the value to T, dereferencing pointers to values, ErrTypeMismatch otherwise.
+// Values are not converted to pointers, as writes through a pointer to a copy would be lost.
+func tryConvert[T any](v any) (T, error) {
+	if typed, ok := v.(T); ok {
+		return typed, nil
+	}
+	value := reflect.ValueOf(v)
+	target := reflect.TypeFor[T]()
+	if value.Kind() == reflect.Pointer && !value.IsNil() && value.Type().Elem() == target {
+		return value.Elem().Interface().(T), nil
+	}
+	var zero T
+	return zero, fmt.Errorf("%w: %T is no %v", ErrTypeMismatch, v, target)
+}
@@ -0,0 +1,129 @@
+package ecs
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_TryGet(t *testing.T) {
+	ecs := New()
+
+	player := createPlayer("player")
+	id := ecs.CreateEntity(&player.PositionComponent).Id()
+
+	// Assertions
+	if _, err := TryGetEntityComponent[*PositionComponent](ecs, 12345); !errors.Is(err, ErrNoSuchEntity) {
+		t.Errorf("err = %v; expected %v", err, ErrNoSuchEntity)
+	}
+	if _, err := TryGetEntityComponent[*VelocityComponent](ecs, id); !errors.Is(err, ErrNoComponent) {
+		t.Errorf("err = %v; expected %v", err, ErrNoComponent)
+	}
+	if p, err := TryGetEntityComponent[PositionComponent](ecs, id); err != nil || p.X != player.X {
+		t.Errorf("position(%d, %v); expected (%d, none)", p.X, err, player.X)
+	}
+	if _, err := TryGetComponentFor[*VelocityComponent](map[uint64]any{id: player.PositionComponent}, id); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("err = %v; expected %v", err, ErrTypeMismatch)
+	}
+	if _, err := TryGetContextFor[*ScoreContext](ecs); !errors.Is(err, ErrNoContext) {
+		t.Errorf("err = %v; expected %v", err, ErrNoContext)
+	}
+	if _, err := ecs.TryAddEntity(42); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("err = %v; expected %v", err, ErrTypeMismatch)
+	}
+	if _, err := ecs.TryAddEntity(player); err != nil {
+		t.Errorf("err = %v; expected none", err)
+	}
+}
+
+func Test_TryGet_ValuesAsPointers(t *testing.T) {
+	ecs := New()
+	id := ecs.CreateEntity(PositionComponent{X: 1}).Id()
+
+	// Pointers to components stored by value point into the storage
+	p, err := TryGetEntityComponent[*PositionComponent](ecs, id)
+	if err == nil {
+		p.X = 99
+	}
+
+	// Assertions
+	if stored := GetEntityComponent[PositionComponent](ecs, id); err != nil || stored.X != 99 {
+		t.Errorf("position(%d, %v); expected (%d, none)", stored.X, err, 99)
+	}
+	if stored := GetComponentsFor[*PositionComponent](ecs)[id]; stored.X != 99 {
+		t.Errorf("position = %d; expected %d", stored.X, 99)
+	}
+	if _, err := TryGetComponentFor[*PositionComponent](map[uint64]any{id: PositionComponent{}}, id); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("err = %v; expected %v, as writes to a copy would be lost", err, ErrTypeMismatch)
+	}
+}
+
+func Test_TryGetComponentsFor_Unregistered(t *testing.T) {
+	ecs := New()
+	ecs.CreateEntity(&PositionComponent{})
+	registered := ecs.Registry().Len()
+
+	// Unknown types are looked up, not registered
+	components, err := TryGetComponentsFor[*VelocityComponent](ecs)
+
+	// Assertions
+	if err != nil || len(components) != 0 {
+		t.Errorf("components(%d, %v); expected (%d, none)", len(components), err, 0)
+	}
+	if ecs.Registry().Len() != registered {
+		t.Errorf("registered = %d; expected %d", ecs.Registry().Len(), registered)
+	}
+}
+
+func Test_ErrorHandler(t *testing.T) {
+	var reported []error
+	ecs := New(WithErrorHandler(func(err error) {
+		reported = append(reported, err)
+	}))
+
+	id := ecs.CreateEntity(&PositionComponent{}).Id()
+	velocity := GetEntityComponent[*VelocityComponent](ecs, id)
+	ecs.AddEntity("player")
+	GetContextFor[ScoreContext](ecs)
+
+	// Assertions
+	if velocity != nil {
+		t.Errorf("velocity = %v; expected nil", velocity)
+	}
+	if len(reported) != 3 || !errors.Is(reported[0], ErrNoComponent) {
+		t.Errorf("reported = %v; expected %d errors", reported, 3)
+	}
+}
+
+func Test_ErrorHandler_Setup(t *testing.T) {
+	var reported []error
+	ecs := New(WithErrorHandler(func(err error) {
+		reported = append(reported, err)
+	}))
+
+	moveSystem := MoveSystem{}
+	renderSystem := RenderSystem{}
+	moveSystem.RunBefore(&renderSystem)
+	renderSystem.RunBefore(&moveSystem)
+	ecs.AddSystem(&moveSystem, &PositionComponent{})
+	ecs.AddSystem(&renderSystem, &PositionComponent{})
+	state := State[GameState](ecs)
+	OnEnter(ecs, GameStateMenu, &RenderSystem{})
+	ecs.CreateEntity(&PositionComponent{})
+	Tag[PositionComponent](ecs)
+
+	// Assertions
+	if len(reported) != 4 {
+		t.Fatalf("reported = %v; expected %d errors", reported, 4)
+	}
+	for i, expected := range []error{ErrCycle, ErrNoState, ErrNoState, ErrStoredComponent} {
+		if !errors.Is(reported[i], expected) {
+			t.Errorf("reported[%d] = %v; expected %v", i, reported[i], expected)
+		}
+	}
+	if state != GameStateMenu {
+		t.Errorf("state = %v; expected the zero value %v", state, GameStateMenu)
+	}
+	if len(ecs.systems.All()) != 1 {
+		t.Errorf("systems = %d; expected %d, failing systems are not kept", len(ecs.systems.All()), 1)
+	}
+}
@@ -0,0 +1,100 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// eventBuffer is the untyped interface of all event queues, to swap them per update
+type eventBuffer interface {
+	swap()
+}
+
+// eventQueue double-buffers the events of one type: read last update's, write this update's
+type eventQueue[T any] struct {
+	mutex sync.Mutex
+
+	// readable during this update
+	previous []T
+	// emitted during this update, readable in the next
+	current     []T
+	subscribers []func(T)
+}
+
+// emit queues the event for the next update and calls all immediate subscribers
+func (this *eventQueue[T]) emit(event T) {
+	this.mutex.Lock()
+	this.current = append(this.current, event)
+	subscribers := this.subscribers
+	this.mutex.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(event)
+	}
+}
+
+// subscribe adds an immediate subscriber
+func (this *eventQueue[T]) subscribe(fn func(T)) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.subscribers = append(slices.Clip(this.subscribers), fn)
+}
+
+// swap drops the events of the last update and makes this update's readable
+func (this *eventQueue[T]) swap() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	clear(this.previous)
+	this.previous, this.current = this.current, this.previous[:0]
+}
+
+// eventQueueFor returns the queue of T, creating it if necessary (safe from parallel systems)
+func eventQueueFor[T any](ecs *ECS) *eventQueue[T] {
+	eType := reflect.TypeFor[T]()
+
+	ecs.eventsMutex.RLock()
+	queue, ok := ecs.events[eType]
+	ecs.eventsMutex.RUnlock()
+	if ok {
+		return queue.(*eventQueue[T])
+	}
+
+	ecs.eventsMutex.Lock()
+	defer ecs.eventsMutex.Unlock()
+	if queue, ok = ecs.events[eType]; !ok {
+		queue = new(eventQueue[T])
+		ecs.events[eType] = queue
+	}
+	return queue.(*eventQueue[T])
+}
+
+// swapEvents makes all events emitted since the last update readable and drops the older ones
+func (this *ECS) swapEvents() {
+	this.eventsMutex.RLock()
+	defer this.eventsMutex.RUnlock()
+
+	for _, queue := range this.events {
+		queue.swap()
+	}
+}
+
+// Emit sends an event to be read by all systems during the next update, safe to call from parallel systems.
+// Immediate subscribers are called right away, on the emitting goroutine.
+func Emit[T any](ecs *ECS, event T) {
+	eventQueueFor[T](ecs).emit(event)
+}
+
+// Events iterates all events of T emitted during the last update, every reader sees all of them
+func Events[T any](ecs *ECS) iter.Seq[T] {
+	return slices.Values(eventQueueFor[T](ecs).previous)
+}
+
+// Subscribe registers an immediate subscriber, called on every Emit of T.
+// In a parallel world it may be called concurrently.
+func Subscribe[T any](ecs *ECS, fn func(event T)) {
+	eventQueueFor[T](ecs).subscribe(fn)
+}
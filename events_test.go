@@ -0,0 +1,65 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+)
+
+type CollisionEvent struct {
+	A, B uint64
+}
+
+type EmitSystem struct {
+	EntitySystem
+}
+
+func (this *EmitSystem) Run(ecs *ECS, dt time.Duration) {
+	for _, entityId := range this.entities {
+		Emit(ecs, CollisionEvent{A: entityId})
+	}
+}
+
+type ReadSystem struct {
+	EntitySystem
+	read []int
+}
+
+func (this *ReadSystem) Run(ecs *ECS, dt time.Duration) {
+	count := 0
+	for range Events[CollisionEvent](ecs) {
+		count++
+	}
+	this.read = append(this.read, count)
+}
+
+func Test_Events(t *testing.T) {
+	ecs := NewParallel()
+	defer ecs.Close()
+
+	emitSystem := EmitSystem{}
+	readSystem := ReadSystem{}
+	immediate := 0
+	ecs.AddSystem(&emitSystem, &PositionComponent{})
+	ecs.AddSystem(&readSystem)
+	Subscribe(ecs, func(event CollisionEvent) {
+		immediate++
+	})
+
+	for i := 0; i < 10; i++ {
+		ecs.CreateEntity(&PositionComponent{})
+	}
+
+	// Events are read during the next update, and only there
+	ecs.Update(33 * time.Millisecond)
+	ecs.Update(33 * time.Millisecond)
+	ecs.RemoveEntity(1)
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if len(readSystem.read) != 3 || readSystem.read[0] != 0 || readSystem.read[1] != 10 || readSystem.read[2] != 10 {
+		t.Errorf("read = %v; expected %v", readSystem.read, []int{0, 10, 10})
+	}
+	if immediate != 29 {
+		t.Errorf("immediate = %d; expected %d", immediate, 29)
+	}
+}
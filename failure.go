@@ -0,0 +1,139 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ErrPanic is wrapped by the SystemError of a recovered panic
+var ErrPanic = errors.New("ecs: system panicked")
+
+// SystemE may be implemented by systems to report failures, Update calls RunE instead of Run then
+type SystemE interface {
+	RunE(ecs *ECS, dt time.Duration) error
+}
+
+// SystemError is the failure of one system run, returned by Update
+type SystemError struct {
+	System System
+	Err    error
+	// the stack of the goroutine which panicked, nil for returned errors
+	Stack []byte
+}
+
+func (this *SystemError) Error() string {
+	return fmt.Sprintf("ecs: system %T failed: %v", this.System, this.Err)
+}
+
+func (this *SystemError) Unwrap() error {
+	return this.Err
+}
+
+// FailurePolicy decides how Update continues after a system failed
+type FailurePolicy int
+
+const (
+	// FailureSkipSystem continues with the next system (default)
+	FailureSkipSystem FailurePolicy = iota
+	// FailureAbortFrame skips all remaining systems of the update, the rest of a parallel group still finishes
+	FailureAbortFrame
+	// FailureDisableSystem continues, but disables systems after their maximum failures (see WithMaxFailures)
+	FailureDisableSystem
+)
+
+// WithFailurePolicy configures how Update continues after a system failed
+func WithFailurePolicy(policy FailurePolicy) Option {
+	return func(ecs *ECS) {
+		ecs.failurePolicy = policy
+	}
+}
+
+// WithMaxFailures sets the failures after which FailureDisableSystem disables a system, 3 by default
+func WithMaxFailures(maxFailures int) Option {
+	return func(ecs *ECS) {
+		ecs.maxFailures = maxFailures
+	}
+}
+
+// workerPanic carries a panic of a pool worker to the goroutine waiting for the job, with the original stack
+type workerPanic struct {
+	value any
+	stack []byte
+}
+
+func (this *workerPanic) String() string {
+	return fmt.Sprint(this.value)
+}
+
+// run runs the system, recovering from its panics, and records its failure
+func (this *ECS) run(s System, dt time.Duration) {
+	if err := this.runSafe(s, dt); err != nil {
+		this.failuresMutex.Lock()
+		this.failures = append(this.failures, err)
+		this.failuresMutex.Unlock()
+	}
+}
+
+// runSafe calls RunE or Run of the system and returns its error or recovered panic as SystemError
+func (this *ECS) runSafe(s System, dt time.Duration) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			if p, ok := r.(*workerPanic); ok {
+				r, stack = p.value, p.stack
+			}
+			// Errors stay matchable via errors.Is, e.g. ErrNoComponent of GetEntityComponent
+			cause := fmt.Errorf("%w: %v", ErrPanic, r)
+			if rErr, ok := r.(error); ok {
+				cause = fmt.Errorf("%w: %w", ErrPanic, rErr)
+			}
+			err = &SystemError{System: s, Err: cause, Stack: stack}
+		}
+	}()
+
+	if systemE, ok := s.(SystemE); ok {
+		if err := systemE.RunE(this, dt); err != nil {
+			return &SystemError{System: s, Err: err}
+		}
+		return nil
+	}
+	s.Run(this, dt)
+	return nil
+}
+
+// applyFailures handles the failures recorded since the last call by policy, returns whether to abort the update
+func (this *ECS) applyFailures() bool {
+	if len(this.failures) == this.checked {
+		return false
+	}
+	failures := this.failures[this.checked:]
+	this.checked = len(this.failures)
+
+	switch this.failurePolicy {
+	case FailureAbortFrame:
+		return true
+	case FailureDisableSystem:
+		for _, err := range failures {
+			s := err.(*SystemError).System
+			if this.failureCounts[s]++; this.failureCounts[s] >= this.maxFailures {
+				this.SetSystemEnabled(s, false)
+			}
+		}
+	default:
+	}
+	return false
+}
+
+// collectFailures returns the joined failures of this update and resets them
+func (this *ECS) collectFailures() error {
+	if len(this.failures) == 0 {
+		return nil
+	}
+	err := errors.Join(this.failures...)
+	clear(this.failures)
+	this.failures = this.failures[:0]
+	this.checked = 0
+	return err
+}
@@ -0,0 +1,125 @@
+package ecs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errOutOfSync = errors.New("out of sync")
+
+type PanicSystem struct {
+	EntitySystem
+	runs int
+}
+
+func (this *PanicSystem) Run(ecs *ECS, dt time.Duration) {
+	this.runs++
+	panic("collision pass broke")
+}
+
+type LookupSystem struct {
+	EntitySystem
+}
+
+func (this *LookupSystem) Run(ecs *ECS, dt time.Duration) {
+	for _, id := range this.Entities() {
+		GetEntityComponent[*VelocityComponent](ecs, id)
+	}
+}
+
+type FailingSystem struct {
+	EntitySystem
+}
+
+func (this *FailingSystem) Run(ecs *ECS, dt time.Duration) {
+}
+
+func (this *FailingSystem) RunE(ecs *ECS, dt time.Duration) error {
+	return errOutOfSync
+}
+
+func Test_Failure(t *testing.T) {
+	for _, ecs := range []*ECS{New(), NewParallel(WithWorkers(2))} {
+		panicSystem := PanicSystem{}
+		stepSystem := StepSystem{}
+		ecs.AddSystem(&panicSystem)
+		ecs.AddSystem(&FailingSystem{})
+		ecs.AddSystem(&stepSystem)
+		err := ecs.Update(33 * time.Millisecond)
+
+		// Assertions
+		var systemErr *SystemError
+		if !errors.Is(err, ErrPanic) || !errors.Is(err, errOutOfSync) || !errors.As(err, &systemErr) {
+			t.Fatalf("err = %v; expected a panic and a returned error", err)
+		}
+		if systemErr.System != &panicSystem || len(systemErr.Stack) == 0 {
+			t.Errorf("system = %T; expected %T with stack", systemErr.System, &panicSystem)
+		}
+		if stepSystem.runs != 1 {
+			t.Errorf("runs = %d; expected %d, skipping the failed systems", stepSystem.runs, 1)
+		}
+		if err := ecs.Update(33 * time.Millisecond); err == nil {
+			t.Errorf("err = %v; expected the failures again", err)
+		}
+		ecs.Close()
+	}
+}
+
+func Test_Failure_Policies(t *testing.T) {
+	ecs := New(WithFailurePolicy(FailureAbortFrame))
+	defer ecs.Close()
+	ecs.AddSystem(&FailingSystem{})
+	stepSystem := StepSystem{}
+	ecs.AddSystem(&stepSystem)
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if stepSystem.runs != 0 {
+		t.Errorf("runs = %d; expected %d after the aborted frame", stepSystem.runs, 0)
+	}
+
+	ecs = New(WithFailurePolicy(FailureDisableSystem), WithMaxFailures(2))
+	defer ecs.Close()
+	panicSystem := PanicSystem{}
+	ecs.AddSystem(&panicSystem)
+	for range 4 {
+		ecs.Update(33 * time.Millisecond)
+	}
+	if panicSystem.runs != 2 || ecs.SystemEnabled(&panicSystem) {
+		t.Errorf("runs = %d; expected %d before being disabled", panicSystem.runs, 2)
+	}
+}
+
+func Test_Failure_ParallelFor(t *testing.T) {
+	ecs := New(WithWorkers(4))
+	defer ecs.Close()
+	for range 1000 {
+		ecs.CreateEntity(&PositionComponent{})
+	}
+	query := NewQuery1[*PositionComponent](ecs)
+	system := NewParallelSystem(func(ecs *ECS, dt time.Duration, id uint64, commands *CommandBuffer) {
+		panic("worker broke")
+	})
+	ecs.AddSystem(system, query)
+
+	// Assertions
+	var systemErr *SystemError
+	if err := ecs.Update(33 * time.Millisecond); !errors.As(err, &systemErr) || systemErr.System != system {
+		t.Errorf("err = %v; expected the worker panic of the parallel system", err)
+	}
+}
+
+func Test_Failure_PanicErrors(t *testing.T) {
+	for _, ecs := range []*ECS{New(), NewParallel(WithWorkers(2))} {
+		ecs.AddSystem(&LookupSystem{}, &PositionComponent{})
+		ecs.CreateEntity(&PositionComponent{})
+		err := ecs.Update(33 * time.Millisecond)
+
+		// Assertions
+		if !errors.Is(err, ErrPanic) || !errors.Is(err, ErrNoComponent) {
+			t.Errorf("err = %v; expected %v wrapping %v", err, ErrPanic, ErrNoComponent)
+		}
+		ecs.Close()
+	}
+}
@@ -0,0 +1,95 @@
+package ecs
+
+import (
+	"errors"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// ErrInvalidParent is returned if a parent is not alive or would make the hierarchy cyclic
+var ErrInvalidParent = errors.New("ecs: invalid parent")
+
+// SetParent attaches the child to the parent, detaching it from its previous parent.
+// Removing the parent removes the child as well.
+func (this *ECS) SetParent(child, parent uint64) error {
+	if !this.IsAlive(EntityID(child)) || !this.IsAlive(EntityID(parent)) || child == parent {
+		return ErrInvalidParent
+	}
+	// The parent must not be a descendant of the child
+	for ancestor, ok := parent, true; ok; ancestor, ok = this.parents[ancestor] {
+		if ancestor == child {
+			return ErrInvalidParent
+		}
+	}
+
+	this.RemoveParent(child)
+	this.parents[child] = parent
+	this.children[parent] = append(this.children[parent], child)
+	return nil
+}
+
+// RemoveParent detaches the child from its parent, making it a root
+func (this *ECS) RemoveParent(child uint64) {
+	parent, ok := this.parents[child]
+	if !ok {
+		return
+	}
+	delete(this.parents, child)
+	this.children[parent] = slices.DeleteFunc(this.children[parent], func(id uint64) bool {
+		return id == child
+	})
+	if len(this.children[parent]) == 0 {
+		delete(this.children, parent)
+	}
+}
+
+// Parent returns the parent of the given entity, if any
+func (this *ECS) Parent(id uint64) (uint64, bool) {
+	parent, ok := this.parents[id]
+	return parent, ok
+}
+
+// Children returns the direct children of the given entity, in order of attachment
+func (this *ECS) Children(id uint64) []uint64 {
+	return slices.Clone(this.children[id])
+}
+
+// Descendants iterates all descendants of the given entity depth-first, every parent before its children
+func (this *ECS) Descendants(id uint64) iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		this.descend(id, func(child, parent uint64) bool {
+			return yield(child)
+		})
+	}
+}
+
+// Hierarchy iterates all child entities with their parent depth-first, starting at the roots in order of their ids.
+// Every parent is yielded before its children, e.g. to propagate transforms from parents to children.
+func (this *ECS) Hierarchy() iter.Seq2[uint64, uint64] {
+	return func(yield func(uint64, uint64) bool) {
+		for _, root := range slices.Sorted(maps.Keys(this.children)) {
+			if _, ok := this.parents[root]; !ok && !this.descend(root, yield) {
+				return
+			}
+		}
+	}
+}
+
+// descend yields all descendants of the given entity with their parent depth-first, false if stopped
+func (this *ECS) descend(id uint64, yield func(uint64, uint64) bool) bool {
+	for _, child := range this.children[id] {
+		if !yield(child, id) || !this.descend(child, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeHierarchy removes all descendants of the given entity and detaches it from its parent
+func (this *ECS) removeHierarchy(id uint64) {
+	for _, child := range this.Children(id) {
+		this.removeEntity(child)
+	}
+	this.RemoveParent(id)
+}
@@ -0,0 +1,71 @@
+package ecs
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func Test_Hierarchy(t *testing.T) {
+	ecs := New()
+
+	root := ecs.CreateEntity(&PositionComponent{}).Id()
+	child1 := ecs.CreateEntity(&PositionComponent{}).Id()
+	child2 := ecs.CreateEntity(&PositionComponent{}).Id()
+	grandchild := ecs.CreateEntity(&PositionComponent{}).Id()
+	for child, parent := range map[uint64]uint64{child1: root, child2: root, grandchild: child1} {
+		if err := ecs.SetParent(child, parent); err != nil {
+			t.Fatalf("err = %v; expected none", err)
+		}
+	}
+
+	// Assertions
+	if parent, ok := ecs.Parent(grandchild); !ok || parent != child1 {
+		t.Errorf("parent = %d; expected %d", parent, child1)
+	}
+	if children := ecs.Children(root); len(children) != 2 {
+		t.Errorf("children = %v; expected %d", children, 2)
+	}
+	descendants := slices.Collect(ecs.Descendants(root))
+	if len(descendants) != 3 || slices.Index(descendants, grandchild) < slices.Index(descendants, child1) {
+		t.Errorf("descendants = %v; expected %d, parents first", descendants, 3)
+	}
+	visited := map[uint64]bool{root: true}
+	for child, parent := range ecs.Hierarchy() {
+		if !visited[parent] {
+			t.Errorf("child %d before parent %d; expected parents first", child, parent)
+		}
+		visited[child] = true
+	}
+	if err := ecs.SetParent(root, grandchild); !errors.Is(err, ErrInvalidParent) {
+		t.Errorf("err = %v; expected %v", err, ErrInvalidParent)
+	}
+}
+
+func Test_Hierarchy_Remove(t *testing.T) {
+	ecs := New()
+
+	root := ecs.CreateEntity(&PositionComponent{}).Id()
+	child := ecs.CreateEntity(&PositionComponent{}).Id()
+	grandchild := ecs.CreateEntity(&PositionComponent{}).Id()
+	other := ecs.CreateEntity(&PositionComponent{}).Id()
+	ecs.SetParent(child, root)
+	ecs.SetParent(grandchild, child)
+	ecs.SetParent(other, root)
+	ecs.SetParent(other, grandchild)
+
+	ecs.RemoveEntity(child)
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if len(ecs.entities) != 1 || !ecs.IsAlive(EntityID(root)) {
+		t.Errorf("entities = %d; expected only the root", len(ecs.entities))
+	}
+	if children := ecs.Children(root); len(children) != 0 {
+		t.Errorf("children = %v; expected none", children)
+	}
+	if len(ecs.parents) != 0 || len(ecs.children) != 0 {
+		t.Errorf("hierarchy(%d, %d); expected to be empty", len(ecs.parents), len(ecs.children))
+	}
+}
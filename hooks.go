@@ -0,0 +1,153 @@
+package ecs
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// componentHooks are the lifecycle callbacks of one component type
+type componentHooks struct {
+	onAdd     []func(id uint64, c any)
+	onRemove  []func(id uint64, c any)
+	onReplace []func(id uint64, old, new any)
+}
+
+// Observer is notified whenever an entity starts or stops having all of its component types
+type Observer struct {
+	ids     []ComponentID
+	onEnter func(id uint64)
+	onExit  func(id uint64)
+}
+
+// matches checks whether the given component ids contain all observed ones
+func (this *Observer) matches(ids map[ComponentID]any) bool {
+	for _, id := range this.ids {
+		if _, ok := ids[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// hooksFor returns the hooks of the given component id, creating them if necessary
+func (this *ECS) hooksFor(id ComponentID) *componentHooks {
+	hooks, ok := this.hooks[id]
+	if !ok {
+		hooks = new(componentHooks)
+		this.hooks[id] = hooks
+	}
+	return hooks
+}
+
+// hasLifecycleListeners checks whether any hook or observer is registered, to skip the bookkeeping otherwise
+func (this *ECS) hasLifecycleListeners() bool {
+	return len(this.hooks) > 0 || len(this.observers) > 0
+}
+
+// fireAdded calls all add hooks of the given components, in order of their ids
+func (this *ECS) fireAdded(id uint64, components map[ComponentID]any) {
+	for _, cId := range slices.Sorted(maps.Keys(components)) {
+		if hooks, ok := this.hooks[cId]; ok {
+			for _, fn := range hooks.onAdd {
+				fn(id, components[cId])
+			}
+		}
+	}
+}
+
+// fireRemoved calls all remove hooks of the given components
+func (this *ECS) fireRemoved(id uint64, components map[ComponentID]any) {
+	for _, cId := range slices.Sorted(maps.Keys(components)) {
+		if hooks, ok := this.hooks[cId]; ok {
+			for _, fn := range hooks.onRemove {
+				fn(id, components[cId])
+			}
+		}
+	}
+}
+
+// fireReplaced calls all replace hooks of the given old and new components
+func (this *ECS) fireReplaced(id uint64, old, new map[ComponentID]any) {
+	for _, cId := range slices.Sorted(maps.Keys(new)) {
+		if hooks, ok := this.hooks[cId]; ok {
+			for _, fn := range hooks.onReplace {
+				fn(id, old[cId], new[cId])
+			}
+		}
+	}
+}
+
+// fireObservers notifies all observers the entity started or stopped matching
+func (this *ECS) fireObservers(id uint64, before, after map[ComponentID]any) {
+	for _, observer := range this.observers {
+		matchedBefore, matchedAfter := observer.matches(before), observer.matches(after)
+		if !matchedBefore && matchedAfter && observer.onEnter != nil {
+			observer.onEnter(id)
+		} else if matchedBefore && !matchedAfter && observer.onExit != nil {
+			observer.onExit(id)
+		}
+	}
+}
+
+// Observe registers an observer, called whenever an entity starts (onEnter) or stops (onExit) having all given types.
+// Either callback may be nil.
+func (this *ECS) Observe(onEnter, onExit func(id uint64), types ...any) *Observer {
+	observer := &Observer{onEnter: onEnter, onExit: onExit}
+	for _, t := range types {
+		observer.ids = append(observer.ids, this.registry.Id(t))
+	}
+	this.observers = append(this.observers, observer)
+	return observer
+}
+
+// RemoveObserver deletes the given observer
+func (this *ECS) RemoveObserver(observer *Observer) {
+	this.observers = slices.DeleteFunc(this.observers, func(o *Observer) bool {
+		return o == observer
+	})
+}
+
+// OnAdd registers a hook, called after a component of type T has been added to an entity
+func OnAdd[T any](ecs *ECS, fn func(id uint64, c T)) {
+	hooks := ecs.hooksFor(ecs.registry.Id(reflect.TypeFor[T]()))
+	hooks.onAdd = append(hooks.onAdd, func(id uint64, c any) {
+		fn(id, convertComponent[T](c))
+	})
+}
+
+// OnRemove registers a hook, called before a component of type T is removed from an entity (also on entity removal),
+// e.g. to release resources it owns
+func OnRemove[T any](ecs *ECS, fn func(id uint64, c T)) {
+	hooks := ecs.hooksFor(ecs.registry.Id(reflect.TypeFor[T]()))
+	hooks.onRemove = append(hooks.onRemove, func(id uint64, c any) {
+		fn(id, convertComponent[T](c))
+	})
+}
+
+// OnReplace registers a hook, called after a component of type T of an entity has been replaced by a new one
+func OnReplace[T any](ecs *ECS, fn func(id uint64, old, new T)) {
+	hooks := ecs.hooksFor(ecs.registry.Id(reflect.TypeFor[T]()))
+	hooks.onReplace = append(hooks.onReplace, func(id uint64, old, new any) {
+		fn(id, convertComponent[T](old), convertComponent[T](new))
+	})
+}
+
+// convertComponent casts the component to T, converting between pointer and value forms (a pointer to a copy)
+func convertComponent[T any](c any) T {
+	if typed, ok := c.(T); ok {
+		return typed
+	}
+	v := reflect.ValueOf(c)
+	target := reflect.TypeFor[T]()
+	if v.Kind() == reflect.Pointer && v.Type().Elem() == target {
+		return v.Elem().Interface().(T)
+	}
+	if target.Kind() == reflect.Pointer && target.Elem() == v.Type() {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface().(T)
+	}
+	var zero T
+	return zero
+}
@@ -0,0 +1,82 @@
+package ecs
+
+import (
+	"testing"
+)
+
+func Test_Hooks(t *testing.T) {
+	ecs := New()
+
+	var added, removed, replaced []uint64
+	OnAdd(ecs, func(id uint64, c *VelocityComponent) {
+		added = append(added, id)
+	})
+	OnRemove(ecs, func(id uint64, c *VelocityComponent) {
+		// The component is still available
+		if _, ok := ecs.components.GetComponent(id, c); !ok {
+			t.Errorf("velocity of %d; expected to be available", id)
+		}
+		removed = append(removed, id)
+	})
+	OnReplace(ecs, func(id uint64, old, new *VelocityComponent) {
+		if old.DX != 2 || new.DX != 3 {
+			t.Errorf("replaced(%d, %d); expected (%d, %d)", old.DX, new.DX, 2, 3)
+		}
+		replaced = append(replaced, id)
+	})
+
+	player1 := createPlayer("player1")
+	e1 := ecs.CreateEntity(&player1.PositionComponent, &player1.VelocityComponent)
+	player2 := createPlayer("player2")
+	e2 := ecs.CreateEntity(&player2.PositionComponent)
+
+	ecs.AddComponents(e2.Id(), &player2.VelocityComponent)
+	ecs.AddComponents(e2.Id(), &VelocityComponent{DX: 3})
+	ecs.RemoveComponents(e2.Id(), VelocityComponent{})
+	ecs.RemoveEntityNow(e1.Id())
+
+	// Assertions
+	if len(added) != 2 || added[0] != e1.Id() || added[1] != e2.Id() {
+		t.Errorf("added = %v; expected %v", added, []uint64{e1.Id(), e2.Id()})
+	}
+	if len(replaced) != 1 || replaced[0] != e2.Id() {
+		t.Errorf("replaced = %v; expected %v", replaced, []uint64{e2.Id()})
+	}
+	if len(removed) != 2 || removed[0] != e2.Id() || removed[1] != e1.Id() {
+		t.Errorf("removed = %v; expected %v", removed, []uint64{e2.Id(), e1.Id()})
+	}
+}
+
+func Test_Observer(t *testing.T) {
+	ecs := New()
+
+	var entered, exited []uint64
+	ecs.Observe(func(id uint64) {
+		entered = append(entered, id)
+	}, func(id uint64) {
+		exited = append(exited, id)
+	}, PositionComponent{}, VelocityComponent{})
+
+	player1 := createPlayer("player1")
+	e1 := ecs.CreateEntity(&player1.PositionComponent)
+	player2 := createPlayer("player2")
+	e2 := ecs.CreateEntity(&player2.PositionComponent, &player2.VelocityComponent)
+
+	// Assertions
+	if len(entered) != 1 || entered[0] != e2.Id() {
+		t.Errorf("entered = %v; expected %v", entered, []uint64{e2.Id()})
+	}
+
+	ecs.AddComponents(e1.Id(), &player1.VelocityComponent)
+	ecs.AddComponents(e1.Id(), &player1.BoundsComponent)
+	ecs.RemoveComponents(e1.Id(), VelocityComponent{})
+	ecs.RemoveEntityNow(e2.Id())
+
+	// Assertions
+	if len(entered) != 2 || entered[1] != e1.Id() {
+		t.Errorf("entered = %v; expected %v", entered, []uint64{e2.Id(), e1.Id()})
+	}
+	if len(exited) != 2 || exited[0] != e1.Id() || exited[1] != e2.Id() {
+		t.Errorf("exited = %v; expected %v", exited, []uint64{e1.Id(), e2.Id()})
+	}
+}
@@ -0,0 +1,116 @@
+package ecs
+
+import (
+	"runtime"
+	"time"
+)
+
+// WithWorkers bounds the worker pool of ParallelFor and ParallelSystem, runtime.GOMAXPROCS by default
+func WithWorkers(workers int) Option {
+	return func(ecs *ECS) {
+		ecs.workers = max(1, workers)
+	}
+}
+
+// Workers returns the size of the worker pool
+func (this *ECS) Workers() int {
+	return this.workers
+}
+
+// defaultWorkers is the pool size without WithWorkers
+func defaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallelFor calls fn for all indices up to n, spread over the worker pool, and waits for all to finish
+func (this *ECS) parallelFor(n int, fn func(worker int, i int)) {
+	if this.workers <= 1 || n <= 1 {
+		for i := range n {
+			fn(0, i)
+		}
+		return
+	}
+	this.pool().run(newJob(fn), n)
+}
+
+// workerBuffers returns one new command buffer per worker and one for the calling goroutine
+func (this *ECS) workerBuffers() []*CommandBuffer {
+	buffers := make([]*CommandBuffer, this.workers+1)
+	for i := range buffers {
+		buffers[i] = NewCommandBuffer(this)
+	}
+	return buffers
+}
+
+// deferBuffers queues the non-empty buffers for playback at the next sync point, safe from parallel systems
+func (this *ECS) deferBuffers(buffers []*CommandBuffer) {
+	this.deferredMutex.Lock()
+	defer this.deferredMutex.Unlock()
+
+	for _, buffer := range buffers {
+		if buffer.Len() > 0 {
+			this.deferred = append(this.deferred, buffer)
+		}
+	}
+}
+
+// playbackDeferred applies all deferred worker buffers in order
+func (this *ECS) playbackDeferred() {
+	this.deferredMutex.Lock()
+	deferred := this.deferred
+	this.deferred = nil
+	this.deferredMutex.Unlock()
+
+	for _, buffer := range deferred {
+		buffer.Playback()
+	}
+}
+
+// chunk is a range of rows of one matching archetype of a query
+type chunk struct {
+	archetype int
+	from, to  int
+}
+
+// chunks splits the rows of all matching archetypes into ranges for the workers, several per worker to balance load
+func (this *query) chunks() []chunk {
+	total := 0
+	for _, a := range this.matching() {
+		total += a.Len()
+	}
+	size := max(64, total/(this.ecs.workers*4))
+
+	var chunks []chunk
+	for i, a := range this.archetypes {
+		for from := 0; from < a.Len(); from += size {
+			chunks = append(chunks, chunk{archetype: i, from: from, to: min(from+size, a.Len())})
+		}
+	}
+	return chunks
+}
+
+// ParallelSystem runs fn for all its entities, chunked across the worker pool of the world.
+// fn must only change the given entity's components, structural changes go to the worker's command buffer,
+// played back at the next sync point.
+type ParallelSystem struct {
+	EntitySystem
+	fn func(ecs *ECS, dt time.Duration, id uint64, commands *CommandBuffer)
+}
+
+func NewParallelSystem(fn func(ecs *ECS, dt time.Duration, id uint64, commands *CommandBuffer)) (this *ParallelSystem) {
+	this = new(ParallelSystem)
+	this.fn = fn
+	return this
+}
+
+func (this *ParallelSystem) Run(ecs *ECS, dt time.Duration) {
+	entities := this.entities
+	size := max(64, len(entities)/(ecs.workers*4))
+	buffers := ecs.workerBuffers()
+	ecs.parallelFor((len(entities)+size-1)/size, func(worker int, i int) {
+		for _, id := range entities[i*size : min((i+1)*size, len(entities))] {
+			this.fn(ecs, dt, id, buffers[worker])
+		}
+	})
+	ecs.deferBuffers(buffers)
+}
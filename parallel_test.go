@@ -0,0 +1,73 @@
+package ecs
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type ParallelMoveSystem struct {
+	EntitySystem
+	query *Query2[*PositionComponent, *VelocityComponent]
+}
+
+func (this *ParallelMoveSystem) Run(ecs *ECS, dt time.Duration) {
+	this.query.ParallelForCommands(func(commands *CommandBuffer, id uint64, p *PositionComponent, v *VelocityComponent) {
+		p.X += v.DX
+		if p.X > 5 {
+			commands.AddComponents(id, StunnedComponent{})
+		}
+	})
+}
+
+func Test_ParallelFor(t *testing.T) {
+	ecs := New(WithWorkers(4))
+	defer ecs.Close()
+	for i := range 1000 {
+		ecs.CreateEntity(&PositionComponent{X: i % 10}, &VelocityComponent{DX: 1})
+	}
+	query := NewQuery2[*PositionComponent, *VelocityComponent](ecs)
+	ecs.AddSystem(&ParallelMoveSystem{query: query}, query)
+
+	var visited atomic.Int64
+	NewQuery1[*PositionComponent](ecs).ParallelFor(func(id uint64, p *PositionComponent) {
+		visited.Add(1)
+	})
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if ecs.Workers() != 4 || visited.Load() != 1000 {
+		t.Errorf("visited(%d, %d); expected (%d, %d)", ecs.Workers(), visited.Load(), 4, 1000)
+	}
+	sum := 0
+	for _, row := range query.Iter() {
+		sum += row.A.X
+	}
+	if sum != 5500 {
+		t.Errorf("sum = %d; expected %d", sum, 5500)
+	}
+	if stunned := NewQuery1[*PositionComponent](ecs).With(StunnedComponent{}).Count(); stunned != 500 {
+		t.Errorf("stunned = %d; expected %d, played back at the sync point", stunned, 500)
+	}
+}
+
+func Test_ParallelSystem(t *testing.T) {
+	ecs := NewParallel(WithWorkers(3))
+	defer ecs.Close()
+	for range 500 {
+		ecs.CreateEntity(&PositionComponent{}, &VelocityComponent{DX: 2})
+	}
+
+	var moved atomic.Int64
+	parallelSystem := NewParallelSystem(func(ecs *ECS, dt time.Duration, id uint64, commands *CommandBuffer) {
+		moved.Add(1)
+		commands.RemoveEntity(id)
+	})
+	ecs.AddSystem(parallelSystem, &PositionComponent{}, &VelocityComponent{})
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if moved.Load() != 500 || len(ecs.entities) != 0 {
+		t.Errorf("moved(%d, %d); expected (%d, %d)", moved.Load(), len(ecs.entities), 500, 0)
+	}
+}
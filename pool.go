@@ -0,0 +1,243 @@
+package ecs
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// job is a batch of indexed work, claimed index by index by all participating workers
+type job struct {
+	fn func(worker int, i int)
+	n  atomic.Int64
+	// the generation of the current run in the high and the next unclaimed index in the low 32 bits
+	claim   atomic.Uint64
+	pending atomic.Int64
+	done    chan struct{}
+	// the first panic of the current run, raised again on the waiting goroutine
+	panicked atomic.Pointer[workerPanic]
+}
+
+func newJob(fn func(worker int, i int)) (this *job) {
+	this = new(job)
+	this.fn = fn
+	this.done = make(chan struct{}, 1)
+	return this
+}
+
+// participate claims and runs indices of the given run until none are left
+func (this *job) participate(worker int, generation uint32) {
+	for {
+		claim := this.claim.Load()
+		i := int(uint32(claim))
+		if uint32(claim>>32) != generation || int64(i) >= this.n.Load() {
+			return
+		}
+		if !this.claim.CompareAndSwap(claim, claim+1) {
+			continue
+		}
+		this.call(worker, i)
+		if this.pending.Add(-1) == 0 {
+			this.done <- struct{}{}
+		}
+	}
+}
+
+// call runs one index, recovering a panic to not crash the worker
+func (this *job) call(worker int, i int) {
+	defer func() {
+		if r := recover(); r != nil {
+			p, ok := r.(*workerPanic)
+			if !ok {
+				p = &workerPanic{value: r, stack: debug.Stack()}
+			}
+			this.panicked.CompareAndSwap(nil, p)
+		}
+	}()
+	this.fn(worker, i)
+}
+
+// token invites a worker to participate in a run of a job
+type token struct {
+	job        *job
+	generation uint32
+}
+
+// workerQueue is the deque of one worker, popped at the back by its owner and stolen from the front by others
+type workerQueue struct {
+	mutex  sync.Mutex
+	tokens []token
+	head   int
+}
+
+func (this *workerQueue) push(t token) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.tokens = append(this.tokens, t)
+}
+
+func (this *workerQueue) pop() (token, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if this.head == len(this.tokens) {
+		return token{}, false
+	}
+	t := this.tokens[len(this.tokens)-1]
+	this.tokens = this.tokens[:len(this.tokens)-1]
+	this.reset()
+	return t, true
+}
+
+func (this *workerQueue) steal() (token, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if this.head == len(this.tokens) {
+		return token{}, false
+	}
+	t := this.tokens[this.head]
+	this.tokens[this.head] = token{}
+	this.head++
+	this.reset()
+	return t, true
+}
+
+// reset reuses the slice from the start once empty, the lock must be held
+func (this *workerQueue) reset() {
+	if this.head == len(this.tokens) {
+		this.tokens = this.tokens[:0]
+		this.head = 0
+	}
+}
+
+// workerPool is a long-lived, work-stealing pool of goroutines
+type workerPool struct {
+	queues []*workerQueue
+	next   atomic.Uint32
+	wake   chan struct{}
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newWorkerPool(workers int) (this *workerPool) {
+	this = new(workerPool)
+	this.queues = make([]*workerQueue, workers)
+	this.wake = make(chan struct{}, workers)
+	this.quit = make(chan struct{})
+	for worker := range this.queues {
+		this.queues[worker] = new(workerQueue)
+	}
+	for worker := range this.queues {
+		this.wg.Add(1)
+		go this.work(worker)
+	}
+	return this
+}
+
+// work runs the tokens of its own queue, steals from the others and sleeps if there are none
+func (this *workerPool) work(worker int) {
+	defer this.wg.Done()
+	for {
+		if t, ok := this.take(worker); ok {
+			t.job.participate(worker, t.generation)
+			continue
+		}
+		select {
+		case <-this.wake:
+		case <-this.quit:
+			return
+		}
+	}
+}
+
+// take pops from the own queue or steals from the next non-empty one
+func (this *workerPool) take(worker int) (token, bool) {
+	if t, ok := this.queues[worker].pop(); ok {
+		return t, true
+	}
+	for i := 1; i < len(this.queues); i++ {
+		if t, ok := this.queues[(worker+i)%len(this.queues)].steal(); ok {
+			return t, true
+		}
+	}
+	return token{}, false
+}
+
+// run executes n indices of the job on the pool and the calling goroutine (as worker len(queues)) and waits for all.
+// A job must not be run concurrently with itself.
+func (this *workerPool) run(j *job, n int) {
+	if n == 0 {
+		return
+	}
+	// Block all claims of the new generation, before opening them with the new size
+	generation := uint32(j.claim.Load()>>32) + 1
+	j.claim.Store(uint64(generation)<<32 | 0xFFFFFFFF)
+	j.n.Store(int64(n))
+	j.pending.Store(int64(n))
+	j.claim.Store(uint64(generation) << 32)
+
+	// Invite as many workers as there is work for, besides the caller
+	invited := min(n-1, len(this.queues))
+	start := int(this.next.Add(uint32(invited)))
+	for i := range invited {
+		this.queues[(start+i)%len(this.queues)].push(token{job: j, generation: generation})
+		select {
+		case this.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	j.participate(len(this.queues), generation)
+	<-j.done
+	if p := j.panicked.Swap(nil); p != nil {
+		panic(p)
+	}
+}
+
+// close stops all workers and waits for them to return
+func (this *workerPool) close() {
+	close(this.quit)
+	this.wg.Wait()
+}
+
+// pool returns the worker pool of this world, starting it on first use
+func (this *ECS) pool() *workerPool {
+	this.poolMutex.Lock()
+	defer this.poolMutex.Unlock()
+
+	if this.workerPool == nil {
+		this.workerPool = newWorkerPool(this.workers)
+	}
+	return this.workerPool
+}
+
+// Close stops the worker pool of this world, it is restarted if used again
+func (this *ECS) Close() {
+	this.Shutdown(context.Background())
+}
+
+// Shutdown stops the worker pool of this world, waiting for running work until the context is done
+func (this *ECS) Shutdown(ctx context.Context) error {
+	this.poolMutex.Lock()
+	pool := this.workerPool
+	this.workerPool = nil
+	this.poolMutex.Unlock()
+	if pool == nil {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		pool.close()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
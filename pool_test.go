@@ -0,0 +1,83 @@
+package ecs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type CountSystem struct {
+	EntitySystem
+	runs atomic.Int64
+}
+
+func (this *CountSystem) Run(ecs *ECS, dt time.Duration) {
+	this.runs.Add(1)
+}
+
+func Test_WorkerPool(t *testing.T) {
+	ecs := New(WithWorkers(4))
+	defer ecs.Close()
+
+	// Nested jobs and reruns of the same job
+	var sum atomic.Int64
+	outer := newJob(func(worker int, i int) {
+		ecs.parallelFor(100, func(worker int, j int) {
+			sum.Add(int64(j))
+		})
+	})
+	for range 10 {
+		ecs.pool().run(outer, 8)
+	}
+
+	// Assertions
+	if sum.Load() != 10*8*4950 {
+		t.Errorf("sum = %d; expected %d", sum.Load(), 10*8*4950)
+	}
+}
+
+func Test_WorkerPool_Update(t *testing.T) {
+	ecs := NewParallel(WithWorkers(4))
+	systems := []*CountSystem{{}, {}, {}}
+	for _, s := range systems {
+		ecs.AddSystem(s, Read[*PositionComponent]())
+	}
+	ecs.Update(time.Millisecond)
+
+	// Assertions
+	allocs := testing.AllocsPerRun(100, func() {
+		ecs.Update(time.Millisecond)
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %v; expected %v", allocs, 0)
+	}
+	for _, s := range systems {
+		if s.runs.Load() != 102 {
+			t.Errorf("runs = %d; expected %d", s.runs.Load(), 102)
+		}
+	}
+	if err := ecs.Shutdown(context.Background()); err != nil || ecs.workerPool != nil {
+		t.Errorf("err = %v; expected none", err)
+	}
+	ecs.Update(time.Millisecond)
+
+	// Clearing the world stops the workers, too
+	ecs.Clear()
+	if ecs.workerPool != nil {
+		t.Errorf("pool = %v; expected nil after clear", ecs.workerPool)
+	}
+}
+
+func Benchmark_ParallelUpdate(b *testing.B) {
+	ecs := NewParallel()
+	defer ecs.Close()
+	for range 8 {
+		ecs.AddSystem(&CountSystem{}, Read[*PositionComponent]())
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ecs.Update(time.Millisecond)
+	}
+}
@@ -0,0 +1,237 @@
+package ecs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownPrefab is returned if a prefab (or one it extends) is not defined
+var ErrUnknownPrefab = errors.New("ecs: unknown prefab")
+
+// Prefab is a named entity template, optionally extending another prefab
+type Prefab struct {
+	name       string
+	extends    string
+	components []any
+}
+
+// Name returns the name of this prefab
+func (this *Prefab) Name() string {
+	return this.name
+}
+
+// Extends inherits all components of the given prefab, own components override inherited ones of the same type
+func (this *Prefab) Extends(parent string) *Prefab {
+	this.extends = parent
+	return this
+}
+
+// DefinePrefab defines (or redefines) a named template of the given components
+func (this *ECS) DefinePrefab(name string, components ...any) *Prefab {
+	prefab := &Prefab{name: name, components: components}
+	this.prefabs[name] = prefab
+	return prefab
+}
+
+// Instantiate creates an entity of deep copies of the prefab components, replaced by the overrides of the same type
+func (this *ECS) Instantiate(name string, overrides ...any) (Entity, error) {
+	components, err := this.prefabComponents(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	components = mergeComponents(components, overrides)
+
+	copies := make([]any, len(components))
+	for i, c := range components {
+		copies[i] = deepCopy(reflect.ValueOf(c)).Interface()
+	}
+	return this.CreateEntity(copies...), nil
+}
+
+// prefabComponents resolves the components of the prefab and all it extends
+func (this *ECS) prefabComponents(name string, visited []string) ([]any, error) {
+	prefab, ok := this.prefabs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPrefab, name)
+	}
+	if slices.Contains(visited, name) {
+		return nil, fmt.Errorf("ecs: prefab %q extends itself: %s", name, strings.Join(append(visited, name), " -> "))
+	}
+	if prefab.extends == "" {
+		return prefab.components, nil
+	}
+
+	inherited, err := this.prefabComponents(prefab.extends, append(visited, name))
+	if err != nil {
+		return nil, err
+	}
+	return mergeComponents(inherited, prefab.components), nil
+}
+
+// mergeComponents replaces the components by the overrides of the same plain type, appending new ones
+func mergeComponents(components, overrides []any) []any {
+	merged := slices.Clone(components)
+	for _, override := range overrides {
+		typ := plainTypeOf(override)
+		i := slices.IndexFunc(merged, func(c any) bool {
+			return plainTypeOf(c) == typ
+		})
+		if i >= 0 {
+			merged[i] = override
+		} else {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
+// deepCopy copies the value including everything it points to, unexported fields are copied shallowly
+func deepCopy(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(deepCopy(v.Field(i)))
+			}
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		// Copy the held value and wrap it into the interface type again
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(deepCopy(v.Elem()))
+		return copied
+	default:
+		return v
+	}
+}
+
+// prefabDefinition is the file format of one prefab, components by registered name (a star prefix for pointers)
+type prefabDefinition struct {
+	Extends    string         `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Components map[string]any `json:"components" yaml:"components"`
+}
+
+// LoadPrefabs defines all prefabs of the given JSON document, see LoadPrefabsWith
+func (this *ECS) LoadPrefabs(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return this.LoadPrefabsWith(data, json.Unmarshal)
+}
+
+// LoadPrefabsWith defines all prefabs of the document, decoded by the given unmarshal function (e.g. yaml.Unmarshal).
+// The document maps prefab names to their definition:
+//
+//	enemy:
+//	  extends: base
+//	  components:
+//	    "*velocity": {DX: 2}
+//
+// Component fields are decoded by their JSON names, all component types must be registered.
+func (this *ECS) LoadPrefabsWith(data []byte, unmarshal func(data []byte, v any) error) error {
+	var definitions map[string]prefabDefinition
+	if err := unmarshal(data, &definitions); err != nil {
+		return err
+	}
+
+	// Decode all first, to not define a part of the document on errors
+	prefabs := make([]*Prefab, 0, len(definitions))
+	for _, name := range slices.Sorted(maps.Keys(definitions)) {
+		definition := definitions[name]
+		prefab := &Prefab{name: name, extends: definition.Extends}
+		for _, key := range slices.Sorted(maps.Keys(definition.Components)) {
+			raw, err := json.Marshal(stringKeys(definition.Components[key]))
+			if err != nil {
+				return fmt.Errorf("ecs: prefab %q: %w", name, err)
+			}
+			componentName, pointer := strings.CutPrefix(key, "*")
+			c, err := this.decodeComponent(componentName, pointer, func(ptr any) error {
+				return json.Unmarshal(raw, ptr)
+			})
+			if err != nil {
+				return fmt.Errorf("ecs: prefab %q: %w", name, err)
+			}
+			prefab.components = append(prefab.components, c.unwrap())
+		}
+		prefabs = append(prefabs, prefab)
+	}
+
+	for _, prefab := range prefabs {
+		this.prefabs[prefab.name] = prefab
+	}
+	return nil
+}
+
+// stringKeys converts maps with non-string keys, as decoded by some YAML libraries, to be marshalled as JSON objects
+func stringKeys(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		converted := make(map[string]any, len(v))
+		for key, value := range v {
+			converted[fmt.Sprint(key)] = stringKeys(value)
+		}
+		return converted
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for key, value := range v {
+			converted[key] = stringKeys(value)
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, value := range v {
+			converted[i] = stringKeys(value)
+		}
+		return converted
+	default:
+		return v
+	}
+}
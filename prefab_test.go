@@ -0,0 +1,125 @@
+package ecs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type InventoryComponent struct {
+	Items []string
+}
+
+type LootComponent struct {
+	Items any
+}
+
+func Test_Prefab(t *testing.T) {
+	ecs := New()
+
+	ecs.DefinePrefab("base", &PositionComponent{X: 1, Y: 1}, &InventoryComponent{Items: []string{"sword"}})
+	ecs.DefinePrefab("enemy", &VelocityComponent{DX: 2}, BoundsComponent{Width: 4}).Extends("base")
+
+	enemy1, err := ecs.Instantiate("enemy", &PositionComponent{X: 5})
+	if err != nil {
+		t.Fatalf("err = %v; expected none", err)
+	}
+	enemy2, _ := ecs.Instantiate("enemy")
+	GetEntityComponent[*InventoryComponent](ecs, enemy1.Id()).Items[0] = "axe"
+
+	// Assertions
+	if components := enemy1.GetComponents(); len(components) != 4 {
+		t.Errorf("components = %d; expected %d", len(components), 4)
+	}
+	if p := GetEntityComponent[*PositionComponent](ecs, enemy1.Id()); p.X != 5 || p.Y != 0 {
+		t.Errorf("position(%d, %d); expected (%d, %d)", p.X, p.Y, 5, 0)
+	}
+	if p := GetEntityComponent[*PositionComponent](ecs, enemy2.Id()); p.X != 1 {
+		t.Errorf("position = %d; expected %d", p.X, 1)
+	}
+	if items := GetEntityComponent[*InventoryComponent](ecs, enemy2.Id()).Items; items[0] != "sword" {
+		t.Errorf("items = %v; expected a deep copy", items)
+	}
+	if _, err := ecs.Instantiate("boss"); !errors.Is(err, ErrUnknownPrefab) {
+		t.Errorf("err = %v; expected %v", err, ErrUnknownPrefab)
+	}
+	ecs.DefinePrefab("base").Extends("enemy")
+	if _, err := ecs.Instantiate("enemy"); err == nil {
+		t.Errorf("err = %v; expected a cycle error", err)
+	}
+}
+
+func Test_Prefab_Load(t *testing.T) {
+	ecs := New()
+	RegisterComponent[PositionComponent](ecs, "position")
+	RegisterComponent[VelocityComponent](ecs, "velocity")
+
+	err := ecs.LoadPrefabs(strings.NewReader(`{
+		"base": {"components": {"*position": {"X": 1, "Y": 2}}},
+		"enemy": {"extends": "base", "components": {"*velocity": {"DX": 3}}}
+	}`))
+	if err != nil {
+		t.Fatalf("err = %v; expected none", err)
+	}
+	enemy, err := ecs.Instantiate("enemy")
+
+	// Assertions
+	if err != nil {
+		t.Fatalf("err = %v; expected none", err)
+	}
+	if p := GetEntityComponent[*PositionComponent](ecs, enemy.Id()); p.Y != 2 {
+		t.Errorf("position = %d; expected %d", p.Y, 2)
+	}
+	if v := GetEntityComponent[*VelocityComponent](ecs, enemy.Id()); v.DX != 3 {
+		t.Errorf("velocity = %d; expected %d", v.DX, 3)
+	}
+	if err := ecs.LoadPrefabs(strings.NewReader(`{"boss": {"components": {"bounds": {}}}}`)); !errors.Is(err, ErrUnregistered) {
+		t.Errorf("err = %v; expected %v", err, ErrUnregistered)
+	}
+}
+
+func Test_Prefab_LoadWith(t *testing.T) {
+	ecs := New()
+	RegisterComponent[PositionComponent](ecs, "position")
+	RegisterComponent[InventoryComponent](ecs, "inventory")
+
+	// YAML libraries may decode nested mappings with interface keys
+	unmarshal := func(data []byte, v any) error {
+		*v.(*map[string]prefabDefinition) = map[string]prefabDefinition{
+			"chest": {Components: map[string]any{
+				"*position":  map[any]any{"X": 1, "Y": 2},
+				"*inventory": map[any]any{"Items": []any{"gold", "key"}},
+			}},
+		}
+		return nil
+	}
+	if err := ecs.LoadPrefabsWith([]byte("chest: ..."), unmarshal); err != nil {
+		t.Fatalf("err = %v; expected none", err)
+	}
+	chest, err := ecs.Instantiate("chest")
+
+	// Assertions
+	if err != nil {
+		t.Fatalf("err = %v; expected none", err)
+	}
+	if p := GetEntityComponent[*PositionComponent](ecs, chest.Id()); p.Y != 2 {
+		t.Errorf("position = %d; expected %d", p.Y, 2)
+	}
+	if inventory := GetEntityComponent[*InventoryComponent](ecs, chest.Id()); len(inventory.Items) != 2 {
+		t.Errorf("items = %v; expected %v", inventory.Items, []string{"gold", "key"})
+	}
+}
+
+func Test_Prefab_DeepCopyInterfaces(t *testing.T) {
+	ecs := New()
+	ecs.DefinePrefab("chest", &LootComponent{Items: map[string]int{"gold": 1}})
+
+	chest1, _ := ecs.Instantiate("chest")
+	chest2, _ := ecs.Instantiate("chest")
+	GetEntityComponent[*LootComponent](ecs, chest1.Id()).Items.(map[string]int)["gold"] = 99
+
+	// Assertions
+	if gold := GetEntityComponent[*LootComponent](ecs, chest2.Id()).Items.(map[string]int)["gold"]; gold != 1 {
+		t.Errorf("gold = %d; expected %d, values held by interfaces are copied too", gold, 1)
+	}
+}
@@ -0,0 +1,808 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+)
+
+// Querier is implemented by all typed queries and may be passed to ECS.AddSystem instead of component types
+type Querier interface {
+	// Types returns the raw component types an entity needs to match
+	Types() []reflect.Type
+	// Excluded returns the plain component types an entity must not have
+	Excluded() []reflect.Type
+	// Access returns the access to all fetched (optional included) and change filtered component types
+	Access() []Access
+}
+
+// query is the untyped base of all typed queries, caching its matching archetypes
+type query struct {
+	ecs *ECS
+
+	// the raw types of the type parameters, their registry ids and whether they are optional
+	types    []reflect.Type
+	ids      []ComponentID
+	optional []bool
+	// additional filters without fetching the components
+	with       []reflect.Type
+	withIds    []ComponentID
+	without    []reflect.Type
+	withoutIds []ComponentID
+	// change detection filters and fetched pointer types which are not marked changed
+	changed    []reflect.Type
+	changedIds []ComponentID
+	added      []reflect.Type
+	addedIds   []ComponentID
+	readOnly   []bool
+
+	// the matching archetypes, their row filters and the amount of storage archetypes checked so far
+	archetypes []*Archetype
+	filters    []rowFilter
+	checked    int
+
+	// the world ticks of the previous and the current iteration, to detect changes in between
+	lastRun uint64
+	thisRun uint64
+}
+
+// rowFilter checks and marks the change detection ticks of the rows of one archetype
+type rowFilter struct {
+	changed []*column
+	added   []*column
+	mutable []*column
+}
+
+// pass checks whether the row got changed or added after the given tick, as filtered
+func (this *rowFilter) pass(row int, since uint64) bool {
+	for _, c := range this.changed {
+		if c.ticks[row].changed <= since {
+			return false
+		}
+	}
+	for _, c := range this.added {
+		if c.ticks[row].added <= since {
+			return false
+		}
+	}
+	return true
+}
+
+// mark flags all mutably fetched components of the row as changed
+func (this *rowFilter) mark(row int, tick uint64) {
+	for _, c := range this.mutable {
+		c.ticks[row].changed = tick
+	}
+}
+
+func newQuery(ecs *ECS, types ...reflect.Type) query {
+	ids := make([]ComponentID, len(types))
+	for i, t := range types {
+		ids[i] = ecs.registry.Id(t)
+	}
+	return query{
+		ecs:      ecs,
+		types:    types,
+		ids:      ids,
+		optional: make([]bool, len(types)),
+		readOnly: make([]bool, len(types)),
+	}
+}
+
+// Types returns the raw component types an entity needs to match
+func (this *query) Types() []reflect.Type {
+	types := make([]reflect.Type, 0, len(this.types)+len(this.with)+len(this.changed)+len(this.added))
+	for i, t := range this.types {
+		if !this.optional[i] {
+			types = append(types, t)
+		}
+	}
+	types = append(types, this.with...)
+	types = append(types, this.changed...)
+	types = append(types, this.added...)
+	return types
+}
+
+// Excluded returns the plain component types an entity must not have
+func (this *query) Excluded() []reflect.Type {
+	return this.without
+}
+
+// Access returns the access to all fetched (optional included) and change filtered component types.
+// Mutable pointer fetches are writes, values, read-only pointers and change filters are reads.
+func (this *query) Access() []Access {
+	access := make([]Access, 0, len(this.types)+len(this.changed)+len(this.added))
+	for i, t := range this.types {
+		access = append(access, Access{Type: t, Write: t.Kind() == reflect.Pointer && !this.readOnly[i]})
+	}
+	for _, t := range this.changed {
+		access = append(access, Access{Type: t})
+	}
+	for _, t := range this.added {
+		access = append(access, Access{Type: t})
+	}
+	return access
+}
+
+// Count returns the amount of matching entities
+func (this *query) Count() int {
+	count := 0
+	for range this.Entities() {
+		count++
+	}
+	return count
+}
+
+// Entities iterates all matching entity ids, without marking any component changed
+func (this *query) Entities() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		since, _ := this.since()
+		for i, a := range this.matching() {
+			for row, eId := range a.entities {
+				if this.filters[i].pass(row, since) && !yield(eId) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// since returns the tick of the previous iteration to filter changes by and the tick to mark changes with
+func (this *query) since() (uint64, uint64) {
+	if this.thisRun != this.ecs.tick {
+		return this.thisRun, this.ecs.tick
+	}
+	return this.lastRun, this.thisRun
+}
+
+// begin starts an iteration, moving the ticks forward if the world advanced since the last one
+func (this *query) begin() (uint64, uint64) {
+	this.lastRun, this.thisRun = this.since()
+	return this.lastRun, this.thisRun
+}
+
+// addWith requires the given component types without fetching them
+func (this *query) addWith(types []any) {
+	for _, t := range types {
+		this.with = append(this.with, typeOf(t))
+		this.withIds = append(this.withIds, this.ecs.registry.Id(t))
+	}
+	this.reset()
+}
+
+// addWithout excludes all entities having any of the given component types
+func (this *query) addWithout(types []any) {
+	for _, t := range types {
+		this.without = append(this.without, plainTypeOf(t))
+		this.withoutIds = append(this.withoutIds, this.ecs.registry.Id(t))
+	}
+	this.reset()
+}
+
+// addChanged requires the given component types, changed since the previous iteration
+func (this *query) addChanged(types []any) {
+	for _, t := range types {
+		this.changed = append(this.changed, typeOf(t))
+		this.changedIds = append(this.changedIds, this.ecs.registry.Id(t))
+	}
+	this.reset()
+}
+
+// addAdded requires the given component types, added since the previous iteration
+func (this *query) addAdded(types []any) {
+	for _, t := range types {
+		this.added = append(this.added, typeOf(t))
+		this.addedIds = append(this.addedIds, this.ecs.registry.Id(t))
+	}
+	this.reset()
+}
+
+// setReadOnly excludes the given fetched pointer types from being marked changed
+func (this *query) setReadOnly(types []any) {
+	for _, t := range types {
+		rId := this.ecs.registry.Id(t)
+		for i, qId := range this.ids {
+			if qId == rId {
+				this.readOnly[i] = true
+			}
+		}
+	}
+	this.reset()
+}
+
+// setOptional marks the given fetched component types as optional, yielding zero values if missing
+func (this *query) setOptional(types []any) {
+	for _, t := range types {
+		oId := this.ecs.registry.Id(t)
+		for i, qId := range this.ids {
+			if qId == oId {
+				this.optional[i] = true
+			}
+		}
+	}
+	this.reset()
+}
+
+// reset drops the cached archetypes
+func (this *query) reset() {
+	this.archetypes = this.archetypes[:0]
+	this.filters = this.filters[:0]
+	this.checked = 0
+}
+
+// matching returns all archetypes of the storage matching this query
+func (this *query) matching() []*Archetype {
+	archetypes := this.ecs.components.archetypes
+	// Archetypes are only ever appended, unless the storage got cleared
+	if this.checked > len(archetypes) {
+		this.reset()
+	}
+	for ; this.checked < len(archetypes); this.checked++ {
+		if a := archetypes[this.checked]; this.matches(a) {
+			this.archetypes = append(this.archetypes, a)
+			this.filters = append(this.filters, this.rowFilter(a))
+		}
+	}
+	return this.archetypes
+}
+
+// matches checks the archetype against all types and filters
+func (this *query) matches(a *Archetype) bool {
+	for i, id := range this.ids {
+		if !this.optional[i] && !a.HasId(id) {
+			return false
+		}
+	}
+	for _, id := range this.withIds {
+		if !a.HasId(id) {
+			return false
+		}
+	}
+	for _, id := range this.withoutIds {
+		if a.HasId(id) {
+			return false
+		}
+	}
+	for _, id := range append(this.changedIds, this.addedIds...) {
+		if !a.HasId(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// rowFilter collects the columns to check and mark per row of the archetype
+func (this *query) rowFilter(a *Archetype) (filter rowFilter) {
+	// Tags have no ticks and always pass
+	for _, id := range this.changedIds {
+		if c := a.columnById(id); c != nil {
+			filter.changed = append(filter.changed, c)
+		}
+	}
+	for _, id := range this.addedIds {
+		if c := a.columnById(id); c != nil {
+			filter.added = append(filter.added, c)
+		}
+	}
+	for i, t := range this.types {
+		if c := a.columnById(this.ids[i]); c != nil && t.Kind() == reflect.Pointer && !this.readOnly[i] {
+			filter.mutable = append(filter.mutable, c)
+		}
+	}
+	return filter
+}
+
+// accessor reads typed components from one archetype column
+type accessor[T any] struct {
+	dense []T
+	col   *column
+	exact bool
+	// the value of tags, which have no column
+	zero T
+}
+
+func newAccessor[T any](a *Archetype, id ComponentID) (this accessor[T]) {
+	this.col = a.columnById(id)
+	if this.col != nil && this.col.typ == reflect.TypeFor[T]() {
+		this.exact = true
+		this.dense = columnData[T](this.col)
+	}
+	if this.col == nil && a.HasId(id) {
+		this.zero = zeroComponent(reflect.TypeFor[T]()).(T)
+	}
+	return this
+}
+
+// get returns the typed component of the given row, the zero value for tags and missing optional columns
+func (this accessor[T]) get(row int) T {
+	if this.exact {
+		return this.dense[row]
+	}
+	if this.col == nil {
+		return this.zero
+	}
+	return castComponent[T](this.col, row)
+}
+
+// castComponent converts between pointer and value forms of the stored component
+func castComponent[T any](c *column, row int) T {
+	v := c.ptr.Elem().Index(row)
+	target := reflect.TypeFor[T]()
+	if target.Kind() == reflect.Pointer && target.Elem() == v.Type() {
+		// Pointer into the dense column
+		return v.Addr().Interface().(T)
+	}
+	if v.Kind() == reflect.Pointer && v.Type().Elem() == target {
+		return v.Elem().Interface().(T)
+	}
+	return v.Interface().(T)
+}
+
+// typeOf returns the raw type from any given value or reflect.Type
+func typeOf(t any) reflect.Type {
+	if typ, ok := t.(reflect.Type); ok {
+		return typ
+	}
+	return reflect.TypeOf(t)
+}
+
+// Row1 is the typed result of a Query1
+type Row1[A any] struct {
+	A A
+}
+
+// Query1 iterates all entities having component A
+type Query1[A any] struct {
+	query
+}
+
+// NewQuery1 creates a typed query on the given world
+func NewQuery1[A any](ecs *ECS) (this *Query1[A]) {
+	this = new(Query1[A])
+	this.query = newQuery(ecs, reflect.TypeFor[A]())
+	return this
+}
+
+// With requires the given component types without fetching them
+func (this *Query1[A]) With(types ...any) *Query1[A] {
+	this.addWith(types)
+	return this
+}
+
+// Without excludes all entities having any of the given component types
+func (this *Query1[A]) Without(types ...any) *Query1[A] {
+	this.addWithout(types)
+	return this
+}
+
+// Optional marks the given fetched component types as optional, yielding zero values if missing
+func (this *Query1[A]) Optional(types ...any) *Query1[A] {
+	this.setOptional(types)
+	return this
+}
+
+// Changed requires the given component types, changed since the previous iteration of this query
+func (this *Query1[A]) Changed(types ...any) *Query1[A] {
+	this.addChanged(types)
+	return this
+}
+
+// Added requires the given component types, added since the previous iteration of this query
+func (this *Query1[A]) Added(types ...any) *Query1[A] {
+	this.addAdded(types)
+	return this
+}
+
+// ReadOnly excludes the given fetched pointer types from being marked changed
+func (this *Query1[A]) ReadOnly(types ...any) *Query1[A] {
+	this.setReadOnly(types)
+	return this
+}
+
+// Iter returns an iterator over all matching entity ids and their components
+func (this *Query1[A]) Iter() iter.Seq2[uint64, Row1[A]] {
+	return this.iter
+}
+
+// Each calls fn for every matching entity
+func (this *Query1[A]) Each(fn func(id uint64, a A)) {
+	for id, row := range this.iter {
+		fn(id, row.A)
+	}
+}
+
+// ParallelFor calls fn for every matching entity, chunked across the worker pool of the world.
+// fn must only change the given components, see ParallelForCommands for structural changes.
+func (this *Query1[A]) ParallelFor(fn func(id uint64, a A)) {
+	this.ParallelForCommands(func(commands *CommandBuffer, id uint64, a A) {
+		fn(id, a)
+	})
+}
+
+// ParallelForCommands calls fn for every matching entity, chunked across the worker pool of the world,
+// with a per worker command buffer, played back at the next sync point
+func (this *Query1[A]) ParallelForCommands(fn func(commands *CommandBuffer, id uint64, a A)) {
+	since, tick := this.begin()
+	chunks := this.chunks()
+	buffers := this.ecs.workerBuffers()
+	this.ecs.parallelFor(len(chunks), func(worker int, i int) {
+		c := chunks[i]
+		a, filter := this.archetypes[c.archetype], &this.filters[c.archetype]
+		ca := newAccessor[A](a, this.ids[0])
+		for row := c.from; row < c.to; row++ {
+			if !filter.pass(row, since) {
+				continue
+			}
+			filter.mark(row, tick)
+			fn(buffers[worker], a.entities[row], ca.get(row))
+		}
+	})
+	this.ecs.deferBuffers(buffers)
+}
+
+func (this *Query1[A]) iter(yield func(uint64, Row1[A]) bool) {
+	since, tick := this.begin()
+	for i, a := range this.matching() {
+		filter := &this.filters[i]
+		ca := newAccessor[A](a, this.ids[0])
+		for row, eId := range a.entities {
+			if !filter.pass(row, since) {
+				continue
+			}
+			filter.mark(row, tick)
+			if !yield(eId, Row1[A]{ca.get(row)}) {
+				return
+			}
+		}
+	}
+}
+
+// Row2 is the typed result of a Query2
+type Row2[A, B any] struct {
+	A A
+	B B
+}
+
+// Query2 iterates all entities having components A and B
+type Query2[A, B any] struct {
+	query
+}
+
+// NewQuery2 creates a typed query on the given world
+func NewQuery2[A, B any](ecs *ECS) (this *Query2[A, B]) {
+	this = new(Query2[A, B])
+	this.query = newQuery(ecs, reflect.TypeFor[A](), reflect.TypeFor[B]())
+	return this
+}
+
+// With requires the given component types without fetching them
+func (this *Query2[A, B]) With(types ...any) *Query2[A, B] {
+	this.addWith(types)
+	return this
+}
+
+// Without excludes all entities having any of the given component types
+func (this *Query2[A, B]) Without(types ...any) *Query2[A, B] {
+	this.addWithout(types)
+	return this
+}
+
+// Optional marks the given fetched component types as optional, yielding zero values if missing
+func (this *Query2[A, B]) Optional(types ...any) *Query2[A, B] {
+	this.setOptional(types)
+	return this
+}
+
+// Changed requires the given component types, changed since the previous iteration of this query
+func (this *Query2[A, B]) Changed(types ...any) *Query2[A, B] {
+	this.addChanged(types)
+	return this
+}
+
+// Added requires the given component types, added since the previous iteration of this query
+func (this *Query2[A, B]) Added(types ...any) *Query2[A, B] {
+	this.addAdded(types)
+	return this
+}
+
+// ReadOnly excludes the given fetched pointer types from being marked changed
+func (this *Query2[A, B]) ReadOnly(types ...any) *Query2[A, B] {
+	this.setReadOnly(types)
+	return this
+}
+
+// Iter returns an iterator over all matching entity ids and their components
+func (this *Query2[A, B]) Iter() iter.Seq2[uint64, Row2[A, B]] {
+	return this.iter
+}
+
+// Each calls fn for every matching entity
+func (this *Query2[A, B]) Each(fn func(id uint64, a A, b B)) {
+	for id, row := range this.iter {
+		fn(id, row.A, row.B)
+	}
+}
+
+// ParallelFor calls fn for every matching entity, chunked across the worker pool of the world.
+// fn must only change the given components, see ParallelForCommands for structural changes.
+func (this *Query2[A, B]) ParallelFor(fn func(id uint64, a A, b B)) {
+	this.ParallelForCommands(func(commands *CommandBuffer, id uint64, a A, b B) {
+		fn(id, a, b)
+	})
+}
+
+// ParallelForCommands calls fn for every matching entity, chunked across the worker pool of the world,
+// with a per worker command buffer, played back at the next sync point
+func (this *Query2[A, B]) ParallelForCommands(fn func(commands *CommandBuffer, id uint64, a A, b B)) {
+	since, tick := this.begin()
+	chunks := this.chunks()
+	buffers := this.ecs.workerBuffers()
+	this.ecs.parallelFor(len(chunks), func(worker int, i int) {
+		c := chunks[i]
+		a, filter := this.archetypes[c.archetype], &this.filters[c.archetype]
+		ca := newAccessor[A](a, this.ids[0])
+		cb := newAccessor[B](a, this.ids[1])
+		for row := c.from; row < c.to; row++ {
+			if !filter.pass(row, since) {
+				continue
+			}
+			filter.mark(row, tick)
+			fn(buffers[worker], a.entities[row], ca.get(row), cb.get(row))
+		}
+	})
+	this.ecs.deferBuffers(buffers)
+}
+
+func (this *Query2[A, B]) iter(yield func(uint64, Row2[A, B]) bool) {
+	since, tick := this.begin()
+	for i, a := range this.matching() {
+		filter := &this.filters[i]
+		ca := newAccessor[A](a, this.ids[0])
+		cb := newAccessor[B](a, this.ids[1])
+		for row, eId := range a.entities {
+			if !filter.pass(row, since) {
+				continue
+			}
+			filter.mark(row, tick)
+			if !yield(eId, Row2[A, B]{ca.get(row), cb.get(row)}) {
+				return
+			}
+		}
+	}
+}
+
+// Row3 is the typed result of a Query3
+type Row3[A, B, C any] struct {
+	A A
+	B B
+	C C
+}
+
+// Query3 iterates all entities having components A, B and C
+type Query3[A, B, C any] struct {
+	query
+}
+
+// NewQuery3 creates a typed query on the given world
+func NewQuery3[A, B, C any](ecs *ECS) (this *Query3[A, B, C]) {
+	this = new(Query3[A, B, C])
+	this.query = newQuery(ecs, reflect.TypeFor[A](), reflect.TypeFor[B](), reflect.TypeFor[C]())
+	return this
+}
+
+// With requires the given component types without fetching them
+func (this *Query3[A, B, C]) With(types ...any) *Query3[A, B, C] {
+	this.addWith(types)
+	return this
+}
+
+// Without excludes all entities having any of the given component types
+func (this *Query3[A, B, C]) Without(types ...any) *Query3[A, B, C] {
+	this.addWithout(types)
+	return this
+}
+
+// Optional marks the given fetched component types as optional, yielding zero values if missing
+func (this *Query3[A, B, C]) Optional(types ...any) *Query3[A, B, C] {
+	this.setOptional(types)
+	return this
+}
+
+// Changed requires the given component types, changed since the previous iteration of this query
+func (this *Query3[A, B, C]) Changed(types ...any) *Query3[A, B, C] {
+	this.addChanged(types)
+	return this
+}
+
+// Added requires the given component types, added since the previous iteration of this query
+func (this *Query3[A, B, C]) Added(types ...any) *Query3[A, B, C] {
+	this.addAdded(types)
+	return this
+}
+
+// ReadOnly excludes the given fetched pointer types from being marked changed
+func (this *Query3[A, B, C]) ReadOnly(types ...any) *Query3[A, B, C] {
+	this.setReadOnly(types)
+	return this
+}
+
+// Iter returns an iterator over all matching entity ids and their components
+func (this *Query3[A, B, C]) Iter() iter.Seq2[uint64, Row3[A, B, C]] {
+	return this.iter
+}
+
+// Each calls fn for every matching entity
+func (this *Query3[A, B, C]) Each(fn func(id uint64, a A, b B, c C)) {
+	for id, row := range this.iter {
+		fn(id, row.A, row.B, row.C)
+	}
+}
+
+// ParallelFor calls fn for every matching entity, chunked across the worker pool of the world.
+// fn must only change the given components, see ParallelForCommands for structural changes.
+func (this *Query3[A, B, C]) ParallelFor(fn func(id uint64, a A, b B, c C)) {
+	this.ParallelForCommands(func(commands *CommandBuffer, id uint64, a A, b B, c C) {
+		fn(id, a, b, c)
+	})
+}
+
+// ParallelForCommands calls fn for every matching entity, chunked across the worker pool of the world,
+// with a per worker command buffer, played back at the next sync point
+func (this *Query3[A, B, C]) ParallelForCommands(fn func(commands *CommandBuffer, id uint64, a A, b B, c C)) {
+	since, tick := this.begin()
+	chunks := this.chunks()
+	buffers := this.ecs.workerBuffers()
+	this.ecs.parallelFor(len(chunks), func(worker int, i int) {
+		c := chunks[i]
+		a, filter := this.archetypes[c.archetype], &this.filters[c.archetype]
+		ca := newAccessor[A](a, this.ids[0])
+		cb := newAccessor[B](a, this.ids[1])
+		cc := newAccessor[C](a, this.ids[2])
+		for row := c.from; row < c.to; row++ {
+			if !filter.pass(row, since) {
+				continue
+			}
+			filter.mark(row, tick)
+			fn(buffers[worker], a.entities[row], ca.get(row), cb.get(row), cc.get(row))
+		}
+	})
+	this.ecs.deferBuffers(buffers)
+}
+
+func (this *Query3[A, B, C]) iter(yield func(uint64, Row3[A, B, C]) bool) {
+	since, tick := this.begin()
+	for i, a := range this.matching() {
+		filter := &this.filters[i]
+		ca := newAccessor[A](a, this.ids[0])
+		cb := newAccessor[B](a, this.ids[1])
+		cc := newAccessor[C](a, this.ids[2])
+		for row, eId := range a.entities {
+			if !filter.pass(row, since) {
+				continue
+			}
+			filter.mark(row, tick)
+			if !yield(eId, Row3[A, B, C]{ca.get(row), cb.get(row), cc.get(row)}) {
+				return
+			}
+		}
+	}
+}
+
+// Row4 is the typed result of a Query4
+type Row4[A, B, C, D any] struct {
+	A A
+	B B
+	C C
+	D D
+}
+
+// Query4 iterates all entities having components A, B, C and D
+type Query4[A, B, C, D any] struct {
+	query
+}
+
+// NewQuery4 creates a typed query on the given world
+func NewQuery4[A, B, C, D any](ecs *ECS) (this *Query4[A, B, C, D]) {
+	this = new(Query4[A, B, C, D])
+	this.query = newQuery(ecs, reflect.TypeFor[A](), reflect.TypeFor[B](), reflect.TypeFor[C](), reflect.TypeFor[D]())
+	return this
+}
+
+// With requires the given component types without fetching them
+func (this *Query4[A, B, C, D]) With(types ...any) *Query4[A, B, C, D] {
+	this.addWith(types)
+	return this
+}
+
+// Without excludes all entities having any of the given component types
+func (this *Query4[A, B, C, D]) Without(types ...any) *Query4[A, B, C, D] {
+	this.addWithout(types)
+	return this
+}
+
+// Optional marks the given fetched component types as optional, yielding zero values if missing
+func (this *Query4[A, B, C, D]) Optional(types ...any) *Query4[A, B, C, D] {
+	this.setOptional(types)
+	return this
+}
+
+// Changed requires the given component types, changed since the previous iteration of this query
+func (this *Query4[A, B, C, D]) Changed(types ...any) *Query4[A, B, C, D] {
+	this.addChanged(types)
+	return this
+}
+
+// Added requires the given component types, added since the previous iteration of this query
+func (this *Query4[A, B, C, D]) Added(types ...any) *Query4[A, B, C, D] {
+	this.addAdded(types)
+	return this
+}
+
+// ReadOnly excludes the given fetched pointer types from being marked changed
+func (this *Query4[A, B, C, D]) ReadOnly(types ...any) *Query4[A, B, C, D] {
+	this.setReadOnly(types)
+	return this
+}
+
+// Iter returns an iterator over all matching entity ids and their components
+func (this *Query4[A, B, C, D]) Iter() iter.Seq2[uint64, Row4[A, B, C, D]] {
+	return this.iter
+}
+
+// Each calls fn for every matching entity
+func (this *Query4[A, B, C, D]) Each(fn func(id uint64, a A, b B, c C, d D)) {
+	for id, row := range this.iter {
+		fn(id, row.A, row.B, row.C, row.D)
+	}
+}
+
+// ParallelFor calls fn for every matching entity, chunked across the worker pool of the world.
+// fn must only change the given components, see ParallelForCommands for structural changes.
+func (this *Query4[A, B, C, D]) ParallelFor(fn func(id uint64, a A, b B, c C, d D)) {
+	this.ParallelForCommands(func(commands *CommandBuffer, id uint64, a A, b B, c C, d D) {
+		fn(id, a, b, c, d)
+	})
+}
+
+// ParallelForCommands calls fn for every matching entity, chunked across the worker pool of the world,
+// with a per worker command buffer, played back at the next sync point
+func (this *Query4[A, B, C, D]) ParallelForCommands(fn func(commands *CommandBuffer, id uint64, a A, b B, c C, d D)) {
+	since, tick := this.begin()
+	chunks := this.chunks()
+	buffers := this.ecs.workerBuffers()
+	this.ecs.parallelFor(len(chunks), func(worker int, i int) {
+		c := chunks[i]
+		a, filter := this.archetypes[c.archetype], &this.filters[c.archetype]
+		ca := newAccessor[A](a, this.ids[0])
+		cb := newAccessor[B](a, this.ids[1])
+		cc := newAccessor[C](a, this.ids[2])
+		cd := newAccessor[D](a, this.ids[3])
+		for row := c.from; row < c.to; row++ {
+			if !filter.pass(row, since) {
+				continue
+			}
+			filter.mark(row, tick)
+			fn(buffers[worker], a.entities[row], ca.get(row), cb.get(row), cc.get(row), cd.get(row))
+		}
+	})
+	this.ecs.deferBuffers(buffers)
+}
+
+func (this *Query4[A, B, C, D]) iter(yield func(uint64, Row4[A, B, C, D]) bool) {
+	since, tick := this.begin()
+	for i, a := range this.matching() {
+		filter := &this.filters[i]
+		ca := newAccessor[A](a, this.ids[0])
+		cb := newAccessor[B](a, this.ids[1])
+		cc := newAccessor[C](a, this.ids[2])
+		cd := newAccessor[D](a, this.ids[3])
+		for row, eId := range a.entities {
+			if !filter.pass(row, since) {
+				continue
+			}
+			filter.mark(row, tick)
+			if !yield(eId, Row4[A, B, C, D]{ca.get(row), cb.get(row), cc.get(row), cd.get(row)}) {
+				return
+			}
+		}
+	}
+}
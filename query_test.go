@@ -0,0 +1,188 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+)
+
+type QueryMoveSystem struct {
+	EntitySystem
+	query *Query2[*PositionComponent, *VelocityComponent]
+}
+
+func (this *QueryMoveSystem) Run(ecs *ECS, dt time.Duration) {
+	for _, row := range this.query.Iter() {
+		row.A.X += row.B.DX
+		row.A.Y += row.B.DY
+	}
+}
+
+func Test_Query(t *testing.T) {
+	ecs := New()
+
+	player1 := createPlayer("player1")
+	ecs.CreateEntity(&player1.PositionComponent, &player1.VelocityComponent)
+	player2 := createPlayer("player2")
+	ecs.CreateEntity(&player2.PositionComponent, &player2.VelocityComponent, &player2.BoundsComponent)
+	player3 := createPlayer("player3")
+	ecs.CreateEntity(&player3.PositionComponent, &player3.BoundsComponent)
+
+	query := NewQuery2[*PositionComponent, *VelocityComponent](ecs)
+	query.Each(func(id uint64, p *PositionComponent, v *VelocityComponent) {
+		p.X += v.DX
+	})
+
+	// Assertions
+	if query.Count() != 2 {
+		t.Errorf("count = %d; expected %d", query.Count(), 2)
+	}
+	if player1.X != 1+player1.DX || player2.X != 1+player2.DX || player3.X != 1 {
+		t.Errorf("players(%d, %d, %d); expected %d, %d, %d", player1.X, player2.X, player3.X, 1+player1.DX, 1+player2.DX, 1)
+	}
+}
+
+func Test_Query_Filters(t *testing.T) {
+	ecs := New()
+
+	player1 := createPlayer("player1")
+	ecs.CreateEntity(&player1.PositionComponent, &player1.VelocityComponent)
+	player2 := createPlayer("player2")
+	ecs.CreateEntity(&player2.PositionComponent, &player2.VelocityComponent, &player2.BoundsComponent)
+	player3 := createPlayer("player3")
+	ecs.CreateEntity(&player3.PositionComponent, &player3.BoundsComponent)
+
+	// Assertions
+	with := NewQuery1[*PositionComponent](ecs).With(&BoundsComponent{})
+	if with.Count() != 2 {
+		t.Errorf("with = %d; expected %d", with.Count(), 2)
+	}
+	without := NewQuery1[*PositionComponent](ecs).Without(BoundsComponent{})
+	if without.Count() != 1 {
+		t.Errorf("without = %d; expected %d", without.Count(), 1)
+	}
+	optional := NewQuery2[*PositionComponent, *VelocityComponent](ecs).Optional(VelocityComponent{})
+	nils := 0
+	for _, row := range optional.Iter() {
+		if row.B == nil {
+			nils++
+		}
+	}
+	if optional.Count() != 3 || nils != 1 {
+		t.Errorf("optional = %d, nils = %d; expected %d, %d", optional.Count(), nils, 3, 1)
+	}
+
+	// New archetypes are picked up by existing queries
+	player4 := createPlayer("player4")
+	ecs.CreateEntity(&player4.PositionComponent, &player4.CommComponent)
+	if without.Count() != 2 {
+		t.Errorf("without = %d; expected %d", without.Count(), 2)
+	}
+}
+
+func Test_Query_ValuesAsPointers(t *testing.T) {
+	ecs := New()
+
+	// Components stored by value can be mutated in place via pointer queries
+	ecs.CreateEntity(PositionComponent{X: 1, Y: 1}, VelocityComponent{DX: 2, DY: 2})
+	NewQuery2[*PositionComponent, VelocityComponent](ecs).Each(func(id uint64, p *PositionComponent, v VelocityComponent) {
+		p.X += v.DX
+	})
+
+	// Assertions
+	for _, row := range NewQuery1[PositionComponent](ecs).Iter() {
+		if row.A.X != 3 {
+			t.Errorf("position = %d; expected %d", row.A.X, 3)
+		}
+	}
+}
+
+func Test_Query_System(t *testing.T) {
+	ecs := New()
+
+	// Systems can be registered with queries instead of types
+	query := NewQuery2[*PositionComponent, *VelocityComponent](ecs).Without(BoundsComponent{})
+	moveSystem := QueryMoveSystem{query: query}
+	ecs.AddSystem(&moveSystem, query)
+
+	player1 := createPlayer("player1")
+	ecs.CreateEntity(&player1.PositionComponent, &player1.VelocityComponent)
+	player2 := createPlayer("player2")
+	ecs.CreateEntity(&player2.PositionComponent, &player2.VelocityComponent, &player2.BoundsComponent)
+
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if len(moveSystem.Entities()) != 1 {
+		t.Errorf("entities = %d; expected %d", len(moveSystem.Entities()), 1)
+	}
+	if player1.X != 1+player1.DX || player2.X != 1 {
+		t.Errorf("players(%d, %d); expected %d, %d", player1.X, player2.X, 1+player1.DX, 1)
+	}
+}
+
+func Test_Query_System_Access(t *testing.T) {
+	ecs := New()
+	storage := NewParallelSystemStorage(ecs)
+
+	// Read-only and value fetches are reads, which may run in parallel
+	readSystem := QueryMoveSystem{}
+	valueSystem := QueryMoveSystem{}
+	storage.AddSystem(&readSystem, NewQuery1[*PositionComponent](ecs).ReadOnly(&PositionComponent{}))
+	storage.AddSystem(&valueSystem, NewQuery1[PositionComponent](ecs))
+
+	// Assertions
+	if len(storage.AllParallel()) != 1 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.AllParallel()), 1)
+	}
+
+	// Optional fetches are accessed, too
+	storage = NewParallelSystemStorage(ecs)
+	optionalSystem := QueryMoveSystem{}
+	writeSystem := QueryMoveSystem{}
+	storage.AddSystem(&optionalSystem, NewQuery2[*VelocityComponent, *PositionComponent](ecs).Optional(&PositionComponent{}))
+	storage.AddSystem(&writeSystem, NewQuery1[*PositionComponent](ecs))
+
+	// Assertions
+	if len(storage.AllParallel()) != 2 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.AllParallel()), 2)
+	}
+}
+
+func Test_Query_NoAllocs(t *testing.T) {
+	ecs := New()
+	for i := 0; i < 100; i++ {
+		player := createPlayer("player")
+		ecs.CreateEntity(&player.PositionComponent, &player.VelocityComponent)
+	}
+	query := NewQuery2[*PositionComponent, *VelocityComponent](ecs)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		for _, row := range query.Iter() {
+			row.A.X += row.B.DX
+		}
+	})
+
+	// Assertions
+	if allocs != 0 {
+		t.Errorf("allocs = %v; expected %v", allocs, 0)
+	}
+}
+
+func Benchmark_Query(b *testing.B) {
+	ecs := New()
+	for i := 0; i < 100; i++ {
+		player := createPlayer("player")
+		ecs.CreateEntity(&player.PositionComponent, &player.VelocityComponent)
+	}
+	query := NewQuery2[*PositionComponent, *VelocityComponent](ecs)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, row := range query.Iter() {
+			row.A.X += row.B.DX
+			row.A.Y += row.B.DY
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ComponentID is the small, per world stable id of a (plain) component type, in order of registration
+type ComponentID uint32
+
+// ComponentInfo describes one registered component type
+type ComponentInfo struct {
+	Id ComponentID
+	// the stable name, empty if only registered implicitly by usage
+	Name string
+	// the plain (non-pointer) type
+	Type reflect.Type
+	// tags are stored as archetype membership only, zero-size types are tags automatically
+	Tag bool
+}
+
+// ComponentRegistry maps component (and context) types to stable ids and names, pointer and value forms share one entry
+type ComponentRegistry struct {
+	mutex sync.RWMutex
+
+	infos []ComponentInfo
+	ids   map[reflect.Type]ComponentID
+	names map[string]ComponentID
+}
+
+func NewComponentRegistry() (this *ComponentRegistry) {
+	this = new(ComponentRegistry)
+	this.ids = make(map[reflect.Type]ComponentID)
+	this.names = make(map[string]ComponentID)
+	return this
+}
+
+// Register names the plain type of the given value or reflect.Type, panics on conflicting registrations
+func (this *ComponentRegistry) Register(t any, name string) ComponentID {
+	typ := plainTypeOf(t)
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if id, ok := this.names[name]; ok && this.infos[id].Type != typ {
+		panic(fmt.Sprintf("ecs: name %q already registered for %v", name, this.infos[id].Type))
+	}
+	id := this.register(typ)
+	if registered := this.infos[id].Name; registered != "" && registered != name {
+		panic(fmt.Sprintf("ecs: type %v already registered as %q", typ, registered))
+	}
+	this.infos[id].Name = name
+	this.names[name] = id
+	return id
+}
+
+// Id returns the id of the given value or reflect.Type, registering it without a name if necessary
+func (this *ComponentRegistry) Id(t any) ComponentID {
+	return this.idOf(plainTypeOf(t))
+}
+
+// Lookup returns the id of the given value or reflect.Type, if registered
+func (this *ComponentRegistry) Lookup(t any) (ComponentID, bool) {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	id, ok := this.ids[plainTypeOf(t)]
+	return id, ok
+}
+
+// Info returns the registration of the given id
+func (this *ComponentRegistry) Info(id ComponentID) (ComponentInfo, bool) {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	if int(id) >= len(this.infos) {
+		return ComponentInfo{}, false
+	}
+	return this.infos[id], true
+}
+
+// Name returns the registered name of the given value or reflect.Type, if explicitly registered
+func (this *ComponentRegistry) Name(t any) (string, bool) {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	if id, ok := this.ids[plainTypeOf(t)]; ok && this.infos[id].Name != "" {
+		return this.infos[id].Name, true
+	}
+	return "", false
+}
+
+// Type returns the plain type registered under the given name
+func (this *ComponentRegistry) Type(name string) (reflect.Type, bool) {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	if id, ok := this.names[name]; ok {
+		return this.infos[id].Type, true
+	}
+	return nil, false
+}
+
+// All returns all registrations in order of their ids, e.g. for debugging tools
+func (this *ComponentRegistry) All() []ComponentInfo {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	return append([]ComponentInfo(nil), this.infos...)
+}
+
+// IsTag checks whether the given value or reflect.Type is a registered tag
+func (this *ComponentRegistry) IsTag(t any) bool {
+	id, ok := this.Lookup(t)
+	return ok && this.isTag(id)
+}
+
+// isTag checks whether the given id is a tag
+func (this *ComponentRegistry) isTag(id ComponentID) bool {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	return int(id) < len(this.infos) && this.infos[id].Tag
+}
+
+// Len returns the amount of registered types
+func (this *ComponentRegistry) Len() int {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	return len(this.infos)
+}
+
+// idOf returns the id of the plain type, registering it if necessary
+func (this *ComponentRegistry) idOf(typ reflect.Type) ComponentID {
+	this.mutex.RLock()
+	id, ok := this.ids[typ]
+	this.mutex.RUnlock()
+	if ok {
+		return id
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.register(typ)
+}
+
+// register adds the plain type if unknown, the lock must be held
+func (this *ComponentRegistry) register(typ reflect.Type) ComponentID {
+	if id, ok := this.ids[typ]; ok {
+		return id
+	}
+	id := ComponentID(len(this.infos))
+	this.infos = append(this.infos, ComponentInfo{Id: id, Type: typ, Tag: typ.Size() == 0})
+	this.ids[typ] = id
+	return id
+}
+
+// RegisterComponent is a convenience generic call to register T under the given name
+func RegisterComponent[T any](ecs *ECS, name string) ComponentID {
+	return ecs.registry.Register(reflect.TypeFor[T](), name)
+}
+
+// Tag declares T a tag, its values are not stored but only the membership of entities.
+// Fails with ErrStoredComponent if T is already stored in a component column, so declare tags before using them.
+func Tag[T any](ecs *ECS) ComponentID {
+	id := ecs.registry.Id(reflect.TypeFor[T]())
+	for _, a := range ecs.components.archetypes {
+		if a.columnById(id) != nil {
+			ecs.fail(fmt.Errorf("%w: %v", ErrStoredComponent, reflect.TypeFor[T]()))
+			return id
+		}
+	}
+
+	ecs.registry.mutex.Lock()
+	defer ecs.registry.mutex.Unlock()
+	ecs.registry.infos[id].Tag = true
+	return id
+}
+
+// ComponentIdFor is a convenience generic call to get the id of T
+func ComponentIdFor[T any](ecs *ECS) ComponentID {
+	return ecs.registry.Id(reflect.TypeFor[T]())
+}
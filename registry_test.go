@@ -0,0 +1,55 @@
+package ecs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Registry(t *testing.T) {
+	ecs := New()
+
+	// Explicit registrations get ids in order
+	positionId := RegisterComponent[PositionComponent](ecs, "position")
+	velocityId := RegisterComponent[*VelocityComponent](ecs, "velocity")
+
+	// Assertions
+	if positionId != 0 || velocityId != 1 {
+		t.Errorf("ids(%d, %d); expected (%d, %d)", positionId, velocityId, 0, 1)
+	}
+	// Pointer and value forms share one entry
+	if id := ComponentIdFor[*PositionComponent](ecs); id != positionId {
+		t.Errorf("id = %d; expected %d", id, positionId)
+	}
+	if id := ecs.Registry().Id(VelocityComponent{}); id != velocityId {
+		t.Errorf("id = %d; expected %d", id, velocityId)
+	}
+	if typ, _ := ecs.Registry().Type("velocity"); typ != reflect.TypeFor[VelocityComponent]() {
+		t.Errorf("type = %v; expected %v", typ, reflect.TypeFor[VelocityComponent]())
+	}
+
+	// Usage registers implicitly, without a name
+	ecs.CreateEntity(&BoundsComponent{})
+	if id, ok := ecs.Registry().Lookup(BoundsComponent{}); !ok || id != 2 {
+		t.Errorf("id = %d; expected %d", id, 2)
+	}
+	if _, ok := ecs.Registry().Name(BoundsComponent{}); ok {
+		t.Errorf("name found; expected none")
+	}
+	// and can be named later, keeping the id
+	if id := RegisterComponent[BoundsComponent](ecs, "bounds"); id != 2 {
+		t.Errorf("id = %d; expected %d", id, 2)
+	}
+}
+
+func Test_Registry_Conflict(t *testing.T) {
+	ecs := New()
+	RegisterComponent[PositionComponent](ecs, "position")
+
+	// Assertions
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic; expected a conflicting name")
+		}
+	}()
+	RegisterComponent[VelocityComponent](ecs, "position")
+}
@@ -0,0 +1,219 @@
+package ecs
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// CleanupPolicy decides what happens to the pairs of a relation when their target entity is removed
+type CleanupPolicy int
+
+const (
+	// CleanupDeletePair removes only the pairs pointing at the removed target (default)
+	CleanupDeletePair CleanupPolicy = iota
+	// CleanupDeleteSource removes the source entities of the pairs pointing at the removed target
+	CleanupDeleteSource
+	// CleanupPanic panics if a target with pairs pointing at it is removed
+	CleanupPanic
+)
+
+// relation stores all pairs of one relation type, indexed in both directions
+type relation struct {
+	policy CleanupPolicy
+	// per source its targets and per target its sources, in order of creation
+	targets map[uint64][]uint64
+	sources map[uint64][]uint64
+}
+
+func newRelation() (this *relation) {
+	this = new(relation)
+	this.targets = make(map[uint64][]uint64)
+	this.sources = make(map[uint64][]uint64)
+	return this
+}
+
+// add stores the pair, if not already stored
+func (this *relation) add(source, target uint64) {
+	if !slices.Contains(this.targets[source], target) {
+		this.targets[source] = append(this.targets[source], target)
+		this.sources[target] = append(this.sources[target], source)
+	}
+}
+
+// remove deletes the pair, if stored
+func (this *relation) remove(source, target uint64) {
+	removeFrom(this.targets, source, target)
+	removeFrom(this.sources, target, source)
+}
+
+// removeFrom deletes the value from the list of the key, dropping empty lists
+func removeFrom(m map[uint64][]uint64, key, value uint64) {
+	if list := slices.DeleteFunc(m[key], func(v uint64) bool { return v == value }); len(list) > 0 {
+		m[key] = list
+	} else {
+		delete(m, key)
+	}
+}
+
+// relationFor returns the relation of the given id, creating it if necessary
+func (this *ECS) relationFor(id ComponentID) *relation {
+	r, ok := this.relations[id]
+	if !ok {
+		r = newRelation()
+		this.relations[id] = r
+	}
+	return r
+}
+
+// checkRemoval panics if removing the entity removes a target of CleanupPanic pairs of a source, which is not removed as well
+func (this *ECS) checkRemoval(id uint64) {
+	ids := slices.Sorted(maps.Keys(this.relations))
+	if !slices.ContainsFunc(ids, func(rId ComponentID) bool { return this.relations[rId].policy == CleanupPanic }) {
+		return
+	}
+
+	removed := this.removalSet(id)
+	for _, rId := range ids {
+		r := this.relations[rId]
+		if r.policy != CleanupPanic {
+			continue
+		}
+		for _, target := range slices.Sorted(maps.Keys(removed)) {
+			for _, source := range r.sources[target] {
+				if !removed[source] {
+					info, _ := this.registry.Info(rId)
+					panic(fmt.Sprintf("ecs: removing entity %d, target of %v pairs", target, info.Type))
+				}
+			}
+		}
+	}
+}
+
+// removalSet collects the entity and all entities its removal cascades into, its descendants and deleted sources
+func (this *ECS) removalSet(id uint64) map[uint64]bool {
+	removed := map[uint64]bool{id: true}
+	for pending := []uint64{id}; len(pending) > 0; {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		next := slices.Clone(this.children[current])
+		for _, r := range this.relations {
+			if r.policy == CleanupDeleteSource {
+				next = append(next, r.sources[current]...)
+			}
+		}
+		for _, n := range next {
+			if !removed[n] {
+				removed[n] = true
+				pending = append(pending, n)
+			}
+		}
+	}
+	return removed
+}
+
+// removeRelations applies the cleanup policies of all pairs pointing at the removed entity and drops its own pairs
+func (this *ECS) removeRelations(id uint64) {
+	for _, rId := range slices.Sorted(maps.Keys(this.relations)) {
+		r := this.relations[rId]
+		for _, target := range slices.Clone(r.targets[id]) {
+			r.remove(id, target)
+		}
+		for _, source := range slices.Clone(r.sources[id]) {
+			r.remove(source, id)
+			if r.policy == CleanupDeleteSource {
+				this.removeEntity(source)
+			}
+		}
+	}
+}
+
+// SetCleanupPolicy configures what happens to the pairs of relation R when their target is removed
+func SetCleanupPolicy[R any](ecs *ECS, policy CleanupPolicy) {
+	ecs.relationFor(ecs.registry.Id(reflect.TypeFor[R]())).policy = policy
+}
+
+// AddPair relates the source to the target via relation R, e.g. AddPair[Targets](world, player, enemy).
+// Both entities must be alive.
+func AddPair[R any](ecs *ECS, source, target uint64) error {
+	if !ecs.IsAlive(EntityID(source)) || !ecs.IsAlive(EntityID(target)) {
+		return fmt.Errorf("ecs: pair %d -> %d of %v: entity not alive", source, target, reflect.TypeFor[R]())
+	}
+	ecs.relationFor(ecs.registry.Id(reflect.TypeFor[R]())).add(source, target)
+	return nil
+}
+
+// RemovePair deletes the pair of relation R between source and target
+func RemovePair[R any](ecs *ECS, source, target uint64) {
+	if r, ok := ecs.relations[ecs.registry.Id(reflect.TypeFor[R]())]; ok {
+		r.remove(source, target)
+	}
+}
+
+// HasPair checks whether the source is related to the target via relation R
+func HasPair[R any](ecs *ECS, source, target uint64) bool {
+	r, ok := ecs.relations[ecs.registry.Id(reflect.TypeFor[R]())]
+	return ok && slices.Contains(r.targets[source], target)
+}
+
+// TargetsOf iterates all targets the source is related to via relation R, in order of creation
+func TargetsOf[R any](ecs *ECS, source uint64) iter.Seq[uint64] {
+	if r, ok := ecs.relations[ecs.registry.Id(reflect.TypeFor[R]())]; ok {
+		return slices.Values(r.targets[source])
+	}
+	return slices.Values([]uint64(nil))
+}
+
+// SourcesOf iterates all sources related to the target via relation R, e.g. all entities targeting an enemy
+func SourcesOf[R any](ecs *ECS, target uint64) iter.Seq[uint64] {
+	if r, ok := ecs.relations[ecs.registry.Id(reflect.TypeFor[R]())]; ok {
+		return slices.Values(r.sources[target])
+	}
+	return slices.Values([]uint64(nil))
+}
+
+// Pairs iterates all source and target pairs of relation R
+func Pairs[R any](ecs *ECS) iter.Seq2[uint64, uint64] {
+	return func(yield func(uint64, uint64) bool) {
+		r, ok := ecs.relations[ecs.registry.Id(reflect.TypeFor[R]())]
+		if !ok {
+			return
+		}
+		for _, source := range slices.Sorted(maps.Keys(r.targets)) {
+			for _, target := range r.targets[source] {
+				if !yield(source, target) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// RelatedTo iterates all sources related to the target via any relation (wildcard), with the relation id
+func (this *ECS) RelatedTo(target uint64) iter.Seq2[uint64, ComponentID] {
+	return func(yield func(uint64, ComponentID) bool) {
+		for _, rId := range slices.Sorted(maps.Keys(this.relations)) {
+			for _, source := range this.relations[rId].sources[target] {
+				if !yield(source, rId) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// RelationsOf iterates all targets the source is related to via any relation (wildcard), with the relation id
+func (this *ECS) RelationsOf(source uint64) iter.Seq2[uint64, ComponentID] {
+	return func(yield func(uint64, ComponentID) bool) {
+		for _, rId := range slices.Sorted(maps.Keys(this.relations)) {
+			for _, target := range this.relations[rId].targets[source] {
+				if !yield(target, rId) {
+					return
+				}
+			}
+		}
+	}
+}
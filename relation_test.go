@@ -0,0 +1,113 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type Targets struct{}
+
+type MemberOf struct{}
+
+func Test_Relation(t *testing.T) {
+	ecs := New()
+
+	player := ecs.CreateEntity(&PositionComponent{}).Id()
+	enemy := ecs.CreateEntity(&PositionComponent{}).Id()
+	squad := ecs.CreateEntity(&PositionComponent{}).Id()
+	AddPair[Targets](ecs, player, enemy)
+	AddPair[Targets](ecs, squad, enemy)
+	AddPair[MemberOf](ecs, player, squad)
+
+	// Assertions
+	if !HasPair[Targets](ecs, player, enemy) || HasPair[Targets](ecs, enemy, player) {
+		t.Errorf("pair(%v, %v); expected (true, false)", HasPair[Targets](ecs, player, enemy), HasPair[Targets](ecs, enemy, player))
+	}
+	if sources := slices.Collect(SourcesOf[Targets](ecs, enemy)); !slices.Equal(sources, []uint64{player, squad}) {
+		t.Errorf("sources = %v; expected %v", sources, []uint64{player, squad})
+	}
+	if targets := slices.Collect(TargetsOf[MemberOf](ecs, player)); !slices.Equal(targets, []uint64{squad}) {
+		t.Errorf("targets = %v; expected %v", targets, []uint64{squad})
+	}
+	related := 0
+	for range ecs.RelationsOf(player) {
+		related++
+	}
+	if related != 2 {
+		t.Errorf("relations = %d; expected %d", related, 2)
+	}
+	if err := AddPair[Targets](ecs, player, 12345); err == nil {
+		t.Errorf("err = %v; expected an error", err)
+	}
+}
+
+func Test_Relation_Cleanup(t *testing.T) {
+	ecs := New()
+	SetCleanupPolicy[MemberOf](ecs, CleanupDeleteSource)
+
+	player := ecs.CreateEntity(&PositionComponent{}).Id()
+	enemy := ecs.CreateEntity(&PositionComponent{}).Id()
+	squad := ecs.CreateEntity(&PositionComponent{}).Id()
+	member := ecs.CreateEntity(&PositionComponent{}).Id()
+	AddPair[Targets](ecs, player, enemy)
+	AddPair[MemberOf](ecs, member, squad)
+
+	ecs.RemoveEntityNow(enemy)
+	ecs.RemoveEntityNow(squad)
+
+	// Assertions
+	if !ecs.IsAlive(EntityID(player)) || HasPair[Targets](ecs, player, enemy) {
+		t.Errorf("player alive = %v; expected the pair to be deleted only", ecs.IsAlive(EntityID(player)))
+	}
+	if ecs.IsAlive(EntityID(member)) {
+		t.Errorf("member alive = %v; expected the source to be deleted", true)
+	}
+}
+
+func Test_Relation_CleanupPanic(t *testing.T) {
+	ecs := New()
+	SetCleanupPolicy[Targets](ecs, CleanupPanic)
+
+	player := ecs.CreateEntity(&PositionComponent{}).Id()
+	enemy := ecs.CreateEntity(&PositionComponent{}).Id()
+	AddPair[Targets](ecs, player, enemy)
+
+	// Assertions
+	defer func() {
+		if r := recover(); r == nil || !ecs.IsAlive(EntityID(enemy)) {
+			t.Errorf("recover = %v; expected a panic, leaving the target alive", r)
+		}
+	}()
+	ecs.RemoveEntityNow(enemy)
+}
+
+func Test_Relation_CleanupPanic_Hierarchy(t *testing.T) {
+	ecs := New()
+	SetCleanupPolicy[Targets](ecs, CleanupPanic)
+
+	player := ecs.CreateEntity(&PositionComponent{}).Id()
+	parent := ecs.CreateEntity(&PositionComponent{}).Id()
+	child1 := ecs.CreateEntity(&PositionComponent{}).Id()
+	child2 := ecs.CreateEntity(&PositionComponent{}).Id()
+	ecs.SetParent(child1, parent)
+	ecs.SetParent(child2, parent)
+	AddPair[Targets](ecs, player, child2)
+
+	// Assertions
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !ecs.IsAlive(EntityID(parent)) || !ecs.IsAlive(EntityID(child1)) {
+				t.Errorf("recover = %v; expected a panic, leaving the whole hierarchy alive", r)
+			}
+		}()
+		ecs.RemoveEntityNow(parent)
+	}()
+
+	// Sources removed along with their targets are no conflict
+	AddPair[Targets](ecs, child1, child2)
+	RemovePair[Targets](ecs, player, child2)
+	ecs.RemoveEntityNow(parent)
+	if len(ecs.entities) != 1 {
+		t.Errorf("entities = %d; expected %d", len(ecs.entities), 1)
+	}
+}
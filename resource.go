@@ -0,0 +1,47 @@
+package ecs
+
+import (
+	"reflect"
+)
+
+// InsertResource stores the singleton resource of type T, replacing any previous one.
+// Resources share their storage with the context, values are stored by pointer to be mutable via ResourceMut.
+// Insert and remove resources outside of parallel system runs.
+func InsertResource[T any](ecs *ECS, resource T) {
+	typ := plainType(reflect.TypeFor[T]())
+	delete(ecs.contextValues, typ)
+	if reflect.TypeFor[T]().Kind() == reflect.Pointer {
+		ecs.context[typ] = resource
+		return
+	}
+	ecs.context[typ] = &resource
+}
+
+// Resource returns the resource of type T (pointer or value form) and whether it exists
+func Resource[T any](ecs *ECS) (T, bool) {
+	resource, ok := ecs.context[plainType(reflect.TypeFor[T]())]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return convertComponent[T](resource), true
+}
+
+// ResourceMut returns a pointer to the resource of plain type T to change it in place, and whether it exists
+func ResourceMut[T any](ecs *ECS) (*T, bool) {
+	// Resources and contexts are always stored by pointer
+	ptr, ok := ecs.context[reflect.TypeFor[T]()].(*T)
+	return ptr, ok
+}
+
+// HasResource checks whether a resource of type T exists
+func HasResource[T any](ecs *ECS) bool {
+	_, ok := ecs.context[plainType(reflect.TypeFor[T]())]
+	return ok
+}
+
+// RemoveResource deletes the resource of type T
+func RemoveResource[T any](ecs *ECS) {
+	delete(ecs.context, plainType(reflect.TypeFor[T]()))
+	delete(ecs.contextValues, plainType(reflect.TypeFor[T]()))
+}
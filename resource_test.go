@@ -0,0 +1,74 @@
+package ecs
+
+import (
+	"testing"
+)
+
+type TimeResource struct {
+	Elapsed int
+}
+
+func Test_Resource(t *testing.T) {
+	ecs := New()
+
+	// Assertions
+	if _, ok := Resource[TimeResource](ecs); ok || HasResource[TimeResource](ecs) {
+		t.Errorf("resource ok = %v; expected none", ok)
+	}
+	InsertResource(ecs, TimeResource{Elapsed: 1})
+	if time, ok := ResourceMut[TimeResource](ecs); ok {
+		time.Elapsed++
+	}
+	if time, ok := Resource[TimeResource](ecs); !ok || time.Elapsed != 2 {
+		t.Errorf("elapsed = %d; expected %d", time.Elapsed, 2)
+	}
+	if time, ok := Resource[*TimeResource](ecs); !ok || time.Elapsed != 2 {
+		t.Errorf("elapsed = %d; expected %d", time.Elapsed, 2)
+	}
+	if context := GetContextFor[*TimeResource](ecs); context.Elapsed != 2 {
+		t.Errorf("context = %d; expected %d", context.Elapsed, 2)
+	}
+	RemoveResource[*TimeResource](ecs)
+	if HasResource[TimeResource](ecs) {
+		t.Errorf("has = %v; expected %v", true, false)
+	}
+
+	// Contexts added by value become mutable
+	ecs.AddContext(ScoreContext{Points: 1})
+	if score, ok := ResourceMut[ScoreContext](ecs); ok {
+		score.Points++
+	}
+	if score, _ := Resource[ScoreContext](ecs); score.Points != 2 {
+		t.Errorf("points = %d; expected %d", score.Points, 2)
+	}
+
+	// Without writing the storage, the context keeps the form it got added in
+	first, _ := ResourceMut[ScoreContext](ecs)
+	second, _ := ResourceMut[ScoreContext](ecs)
+	if score, ok := ecs.GetContext(ScoreContext{}).(ScoreContext); first != second || !ok || score.Points != 2 {
+		t.Errorf("context = %v, %v; expected the same pointer and a value of %d points", first != second, score, 2)
+	}
+}
+
+func Test_Parallelize_Resource(t *testing.T) {
+	ecs := New()
+	storage := NewParallelSystemStorage(ecs)
+
+	// Readers of a resource run in parallel, even if a component of the same type is written
+	collisionSystem := CollisionSystem{}
+	moveSystem := MoveSystem{}
+	storage.AddSystem(&collisionSystem, Read[*PositionComponent](), ReadResource[TimeResource]())
+	storage.AddSystem(&moveSystem, Read[*PositionComponent](), Write[*TimeResource](), ReadResource[*TimeResource]())
+
+	// Assertions
+	if len(storage.AllParallel()) != 1 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.AllParallel()), 1)
+	}
+
+	// A resource writer runs exclusively
+	renderSystem := RenderSystem{}
+	storage.AddSystem(&renderSystem, WriteResource[TimeResource]())
+	if len(storage.AllParallel()) != 2 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.AllParallel()), 2)
+	}
+}
@@ -0,0 +1,163 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FixedStepper may be implemented by systems to run on the fixed instead of the variable timestep of a Runner
+type FixedStepper interface {
+	Fixed() bool
+}
+
+// isFixed checks whether the given system runs on the fixed timestep
+func isFixed(s System) bool {
+	if stepper, ok := s.(FixedStepper); ok {
+		return stepper.Fixed()
+	}
+	return false
+}
+
+// timestep selects the systems of an update
+type timestep int
+
+const (
+	timestepAny timestep = iota
+	timestepFixed
+	timestepVariable
+)
+
+// includes checks whether the system runs on this timestep
+func (this timestep) includes(s System) bool {
+	return this == timestepAny || isFixed(s) == (this == timestepFixed)
+}
+
+// FixedTime is the resource a Runner exposes to all systems
+type FixedTime struct {
+	// the fixed timestep
+	Step time.Duration
+	// the amount of fixed steps of the current frame
+	Steps int
+	// the fraction of a fixed step left over in the current frame, to interpolate between the last two fixed states
+	Alpha float64
+}
+
+// Runner owns the update loop of a world, running fixed timestep systems (e.g. physics, networking) at a constant rate
+// and all others once per frame with the variable frame time
+type Runner struct {
+	ecs         *ECS
+	step        time.Duration
+	maxSteps    int
+	scale       float64
+	paused      bool
+	accumulator time.Duration
+}
+
+// NewRunner creates a runner of the given world with the given fixed timestep, at most 5 fixed steps per frame.
+// Panics if the step is not positive.
+func NewRunner(ecs *ECS, step time.Duration) (this *Runner) {
+	if step <= 0 {
+		panic(fmt.Sprintf("ecs: non-positive fixed step %v for NewRunner", step))
+	}
+	this = new(Runner)
+	this.ecs = ecs
+	this.step = step
+	this.maxSteps = 5
+	this.scale = 1
+	InsertResource(ecs, FixedTime{Step: step})
+	return this
+}
+
+// SetMaxSteps caps the fixed steps per frame, dropping the remaining time to not spiral into ever longer frames
+func (this *Runner) SetMaxSteps(maxSteps int) *Runner {
+	this.maxSteps = maxSteps
+	return this
+}
+
+// SetTimeScale plays the world slower (< 1) or faster (> 1)
+func (this *Runner) SetTimeScale(scale float64) *Runner {
+	this.scale = scale
+	return this
+}
+
+// TimeScale returns the current time scale
+func (this *Runner) TimeScale() float64 {
+	return this.scale
+}
+
+// Pause stops the time, fixed systems do not run and variable systems run with a zero frame time
+func (this *Runner) Pause() {
+	this.paused = true
+}
+
+// Resume continues the time after a pause
+func (this *Runner) Resume() {
+	this.paused = false
+}
+
+// Paused returns whether the time is stopped
+func (this *Runner) Paused() bool {
+	return this.paused
+}
+
+// Frame advances the world by the given real frame time: as many fixed steps as accumulated, then one variable update.
+// State transitions, entity removals and events advance once per frame, before all steps.
+// Returns the joined failures of the frame.
+func (this *Runner) Frame(dt time.Duration) error {
+	if this.paused {
+		dt = 0
+	}
+	dt = time.Duration(float64(dt) * this.scale)
+
+	this.ecs.lockFrame()
+	defer this.ecs.unlockFrame()
+	running := this.ecs.beginFrame(dt)
+
+	this.accumulator += dt
+	steps := 0
+	for this.accumulator >= this.step && steps < this.maxSteps {
+		if running {
+			running = this.ecs.runSystems(this.step, timestepFixed)
+		}
+		this.accumulator -= this.step
+		steps++
+	}
+	// Drop what could not be caught up on
+	if this.accumulator >= this.step {
+		this.accumulator %= this.step
+	}
+
+	if fixedTime, ok := ResourceMut[FixedTime](this.ecs); ok {
+		fixedTime.Step = this.step
+		fixedTime.Steps = steps
+		fixedTime.Alpha = float64(this.accumulator) / float64(this.step)
+	}
+	if running {
+		this.ecs.runSystems(dt, timestepVariable)
+	}
+	return this.ecs.collectFailures()
+}
+
+// Run calls Frame with the measured real time every given frame interval, until the context is done.
+// Frame failures are reported to the error handler of the world, without one Run returns the first.
+func (this *Runner) Run(ctx context.Context, frame time.Duration) error {
+	ticker := time.NewTicker(frame)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if err := this.Frame(now.Sub(last)); err != nil {
+				if this.ecs.errorHandler == nil {
+					return err
+				}
+				this.ecs.errorHandler(err)
+			}
+			last = now
+		}
+	}
+}
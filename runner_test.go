@@ -0,0 +1,117 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type StepSystem struct {
+	EntitySystem
+	runs    int
+	elapsed time.Duration
+	alpha   float64
+}
+
+func (this *StepSystem) Run(ecs *ECS, dt time.Duration) {
+	this.runs++
+	this.elapsed += dt
+	if fixedTime, ok := Resource[FixedTime](ecs); ok {
+		this.alpha = fixedTime.Alpha
+	}
+}
+
+func Test_Runner(t *testing.T) {
+	ecs := New()
+	physicsSystem := StepSystem{}
+	physicsSystem.InFixedStep()
+	renderSystem := StepSystem{}
+	ecs.AddSystem(&physicsSystem)
+	ecs.AddSystem(&renderSystem)
+
+	runner := NewRunner(ecs, 10*time.Millisecond)
+	runner.Frame(25 * time.Millisecond)
+
+	// Assertions
+	if physicsSystem.runs != 2 || physicsSystem.elapsed != 20*time.Millisecond {
+		t.Errorf("fixed(%d, %v); expected (%d, %v)", physicsSystem.runs, physicsSystem.elapsed, 2, 20*time.Millisecond)
+	}
+	if renderSystem.runs != 1 || renderSystem.alpha != 0.5 {
+		t.Errorf("variable(%d, %v); expected (%d, %v)", renderSystem.runs, renderSystem.alpha, 1, 0.5)
+	}
+
+	// Spiral of death is capped
+	runner.SetMaxSteps(3).Frame(time.Second)
+	if physicsSystem.runs != 5 {
+		t.Errorf("fixed = %d; expected %d", physicsSystem.runs, 5)
+	}
+
+	// Paused time only runs variable systems without time
+	runner.Pause()
+	runner.Frame(time.Second)
+	if physicsSystem.runs != 5 || renderSystem.runs != 3 || renderSystem.elapsed != 1025*time.Millisecond {
+		t.Errorf("paused(%d, %d, %v); expected (%d, %d, %v)", physicsSystem.runs, renderSystem.runs, renderSystem.elapsed, 5, 3, 1025*time.Millisecond)
+	}
+
+	// Time scale
+	runner.Resume()
+	runner.SetTimeScale(0.5).Frame(40 * time.Millisecond)
+	if physicsSystem.runs != 7 {
+		t.Errorf("fixed = %d; expected %d", physicsSystem.runs, 7)
+	}
+}
+
+func Test_Runner_Events(t *testing.T) {
+	ecs := New()
+	physicsSystem := StepSystem{}
+	physicsSystem.InFixedStep()
+	emitSystem := EmitSystem{}
+	readSystem := ReadSystem{}
+	ecs.AddSystem(&physicsSystem)
+	ecs.AddSystem(&emitSystem, &PositionComponent{})
+	ecs.AddSystem(&readSystem)
+	ecs.CreateEntity(&PositionComponent{})
+
+	// Events of the variable update are readable in the next frame, in spite of the fixed steps in between
+	runner := NewRunner(ecs, 10*time.Millisecond)
+	for range 10 {
+		runner.Frame(16 * time.Millisecond)
+	}
+
+	// Assertions
+	read := 0
+	for _, count := range readSystem.read {
+		read += count
+	}
+	if physicsSystem.runs != 16 || read != 9 {
+		t.Errorf("frames(%d, %d); expected (%d, %d)", physicsSystem.runs, read, 16, 9)
+	}
+}
+
+func Test_Runner_InvalidStep(t *testing.T) {
+	ecs := New()
+
+	// Assertions
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("recover = %v; expected a panic", r)
+		}
+	}()
+	NewRunner(ecs, 0)
+}
+
+func Test_Runner_Run(t *testing.T) {
+	ecs := New()
+	renderSystem := StepSystem{}
+	ecs.AddSystem(&renderSystem)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := NewRunner(ecs, 10*time.Millisecond).Run(ctx, 5*time.Millisecond)
+
+	// Assertions
+	if !errors.Is(err, context.DeadlineExceeded) || renderSystem.runs == 0 {
+		t.Errorf("run(%v, %d); expected (%v, > 0)", err, renderSystem.runs, context.DeadlineExceeded)
+	}
+}
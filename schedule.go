@@ -0,0 +1,186 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrCycle is returned if the declared system order cannot be satisfied
+var ErrCycle = errors.New("ecs: system order contains a cycle")
+
+// Stage is a named section of an update, all systems of a stage run before the next stage
+type Stage int
+
+const (
+	StagePreUpdate Stage = iota - 1
+	StageUpdate
+	StagePostUpdate
+	StageRender
+)
+
+func (this Stage) String() string {
+	switch this {
+	case StagePreUpdate:
+		return "PreUpdate"
+	case StageUpdate:
+		return "Update"
+	case StagePostUpdate:
+		return "PostUpdate"
+	case StageRender:
+		return "Render"
+	default:
+		return fmt.Sprintf("Stage(%d)", int(this))
+	}
+}
+
+// Stager may be implemented by systems to run in another than the default StageUpdate
+type Stager interface {
+	Stage() Stage
+}
+
+// Orderer may be implemented by systems to declare an explicit order against other systems
+type Orderer interface {
+	Before() []System
+	After() []System
+}
+
+// stageOf returns the stage of the given system
+func stageOf(s System) Stage {
+	if stager, ok := s.(Stager); ok {
+		return stager.Stage()
+	}
+	return StageUpdate
+}
+
+// schedule orders all systems by stage, declared order and priority and groups them for parallel execution
+func (this *SystemStorage) schedule() error {
+	this.err = nil
+	systems := this.sort()
+
+	order, predecessors, err := this.topological(systems)
+	if err != nil {
+		// Keep the priority order to stay runnable
+		this.err = err
+		this.systems = systems
+		this.predecessors = nil
+	} else {
+		this.systems = order
+		this.predecessors = predecessors
+	}
+	this.parallelize()
+
+	return this.err
+}
+
+// topological sorts the given priority ordered systems per stage by their declared order, preferring higher priorities
+func (this *SystemStorage) topological(systems []System) ([]System, map[System][]System, error) {
+	successors := make(map[System][]System)
+	predecessors := make(map[System][]System)
+	addEdge := func(a, b System) error {
+		if _, ok := this.systemTypes[b]; !ok || this.unscheduled[b] {
+			// ignore not (yet) registered and unscheduled systems
+			return nil
+		}
+		if stageOf(a) > stageOf(b) {
+			return fmt.Errorf("%w: %T (%s) cannot run before %T (%s)", ErrCycle, a, stageOf(a), b, stageOf(b))
+		}
+		if stageOf(a) == stageOf(b) && !slices.Contains(successors[a], b) {
+			successors[a] = append(successors[a], b)
+			predecessors[b] = append(predecessors[b], a)
+		}
+		return nil
+	}
+	for _, s := range systems {
+		if orderer, ok := s.(Orderer); ok {
+			for _, b := range orderer.Before() {
+				if err := addEdge(s, b); err != nil {
+					return nil, nil, err
+				}
+			}
+			for _, a := range orderer.After() {
+				if _, ok := this.systemTypes[a]; !ok {
+					continue
+				}
+				if err := addEdge(a, s); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	// Stages in order
+	stages := make([]Stage, 0)
+	for _, s := range systems {
+		if !slices.Contains(stages, stageOf(s)) {
+			stages = append(stages, stageOf(s))
+		}
+	}
+	slices.Sort(stages)
+
+	// Kahn per stage, picking the first available by priority
+	order := make([]System, 0, len(systems))
+	inDegree := make(map[System]int, len(systems))
+	for _, s := range systems {
+		inDegree[s] = len(predecessors[s])
+	}
+	for _, stage := range stages {
+		remaining := slices.DeleteFunc(slices.Clone(systems), func(s System) bool {
+			return stageOf(s) != stage
+		})
+		for len(remaining) > 0 {
+			i := slices.IndexFunc(remaining, func(s System) bool {
+				return inDegree[s] == 0
+			})
+			if i < 0 {
+				return nil, nil, this.cycleError(remaining, successors)
+			}
+			next := remaining[i]
+			remaining = slices.Delete(remaining, i, i+1)
+			order = append(order, next)
+			for _, b := range successors[next] {
+				inDegree[b]--
+			}
+		}
+	}
+
+	return order, predecessors, nil
+}
+
+// cycleError finds one cycle within the given blocked systems to report it
+func (this *SystemStorage) cycleError(systems []System, successors map[System][]System) error {
+	visited := make(map[System]bool)
+	var path []System
+	var visit func(s System) []System
+	visit = func(s System) []System {
+		if i := slices.Index(path, s); i >= 0 {
+			return append(path[i:], s)
+		}
+		if visited[s] {
+			return nil
+		}
+		visited[s] = true
+		path = append(path, s)
+		for _, b := range successors[s] {
+			if slices.Contains(systems, b) {
+				if cycle := visit(b); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return nil
+	}
+
+	for _, s := range systems {
+		if cycle := visit(s); cycle != nil {
+			names := make([]string, len(cycle))
+			for i, c := range cycle {
+				names[i] = fmt.Sprintf("%T", c)
+			}
+			return fmt.Errorf("%w: %s", ErrCycle, strings.Join(names, " -> "))
+		}
+	}
+	return ErrCycle
+}
@@ -0,0 +1,103 @@
+package ecs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type RenderSystem struct {
+	EntitySystem
+}
+
+func (this *RenderSystem) Run(ecs *ECS, dt time.Duration) {
+}
+
+func Test_Schedule_Order(t *testing.T) {
+	ecs := New()
+	storage := NewSystemStorage(ecs, false)
+
+	// Collision has the lower priority, but is declared to run before move
+	collisionSystem := CollisionSystem{}
+	moveSystem := MoveSystem{}
+	collisionSystem.RunBefore(&moveSystem)
+	storage.AddSystem(&collisionSystem, &PositionComponent{}, &BoundsComponent{})
+	storage.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
+
+	// Assertions
+	if storage.Err() != nil || storage.All()[0] != &collisionSystem {
+		t.Errorf("system[0] = %v; expected %v", storage.All()[0], &collisionSystem)
+	}
+}
+
+func Test_Schedule_Stages(t *testing.T) {
+	ecs := New()
+	storage := NewParallelSystemStorage(ecs)
+
+	// Render runs last, pre-update first, even without any conflicts
+	renderSystem := RenderSystem{}
+	renderSystem.InStage(StageRender)
+	commSystem := SpawnSystem{}
+	commSystem.InStage(StagePreUpdate)
+	moveSystem := MoveSystem{}
+	collisionSystem := CollisionSystem{}
+	storage.AddSystem(&renderSystem, Read[*PositionComponent]())
+	storage.AddSystem(&commSystem, &CommComponent{})
+	storage.AddSystem(&moveSystem, Write[*PositionComponent](), Read[*VelocityComponent]())
+	storage.AddSystem(&collisionSystem, Read[*BoundsComponent]())
+
+	// Assertions
+	groups := storage.AllParallel()
+	if len(groups) != 3 {
+		t.Fatalf("parallelSystems = %v; expected %v", len(groups), 3)
+	}
+	if groups[0][0] != &commSystem || len(groups[1]) != 2 || groups[2][0] != &renderSystem {
+		t.Errorf("parallelSystems = %v; expected pre-update, update and render", groups)
+	}
+}
+
+func Test_Schedule_Levels(t *testing.T) {
+	ecs := New()
+	storage := NewParallelSystemStorage(ecs)
+
+	// Without conflicts, only the declared order splits the levels
+	moveSystem := MoveSystem{}
+	collisionSystem := CollisionSystem{}
+	renderSystem := RenderSystem{}
+	renderSystem.RunAfter(&moveSystem)
+	storage.AddSystem(&moveSystem, &VelocityComponent{})
+	storage.AddSystem(&collisionSystem, &BoundsComponent{})
+	storage.AddSystem(&renderSystem, &PositionComponent{})
+
+	// Assertions
+	groups := storage.AllParallel()
+	if len(groups) != 2 || len(groups[0]) != 2 || groups[1][0] != &renderSystem {
+		t.Errorf("parallelSystems = %v; expected move and collision before render", groups)
+	}
+}
+
+func Test_Schedule_Cycle(t *testing.T) {
+	ecs := New()
+
+	moveSystem := MoveSystem{}
+	collisionSystem := CollisionSystem{}
+	renderSystem := RenderSystem{}
+	moveSystem.RunBefore(&collisionSystem)
+	collisionSystem.RunBefore(&renderSystem)
+	renderSystem.RunBefore(&moveSystem)
+	ecs.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
+	ecs.AddSystem(&collisionSystem, &PositionComponent{}, &BoundsComponent{})
+
+	// Assertions
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrCycle) {
+			t.Errorf("err = %v; expected %v", err, ErrCycle)
+		}
+		// The failing system is not kept
+		if len(ecs.systems.All()) != 2 || ecs.systems.Err() != nil {
+			t.Errorf("systems = %v; expected %v", len(ecs.systems.All()), 2)
+		}
+	}()
+	ecs.AddSystem(&renderSystem, &PositionComponent{})
+}
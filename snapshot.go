@@ -0,0 +1,565 @@
+package ecs
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrUnregistered is returned if a component or context type is not known to the registry
+var ErrUnregistered = errors.New("ecs: type not registered")
+
+// SnapshotFormat selects the encoding of a snapshot
+type SnapshotFormat int
+
+const (
+	// SnapshotJSON is human-readable for debugging
+	SnapshotJSON SnapshotFormat = iota
+	// SnapshotBinary is a compact gob stream for save games
+	SnapshotBinary
+)
+
+const snapshotVersion = 1
+
+// snapshotMagic prefixes binary snapshots to tell them apart from JSON on restore
+var snapshotMagic = []byte("ECSB")
+
+var entityIDType = reflect.TypeFor[EntityID]()
+
+// snapshotComponent is one component or context value with its registered name
+type snapshotComponent struct {
+	name    string
+	pointer bool
+	// always a pointer to the plain value
+	value reflect.Value
+}
+
+// snapshotEntity is one entity with all its components
+type snapshotEntity struct {
+	id         uint64
+	components []snapshotComponent
+}
+
+// snapshotPair is one relation pair between two entities, the child and its parent if the relation is empty
+type snapshotPair struct {
+	relation string
+	source   uint64
+	target   uint64
+}
+
+// worldSnapshot is the format independent content of a snapshot
+type worldSnapshot struct {
+	entities []snapshotEntity
+	contexts []snapshotComponent
+	pairs    []snapshotPair
+}
+
+// Snapshot writes all entities, their components, the hierarchy, all relation pairs and all context values of registered types
+// in the given format. All component and relation types must be registered, see RegisterComponent.
+func (this *ECS) Snapshot(w io.Writer, format SnapshotFormat) error {
+	snapshot, err := this.collectSnapshot()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case SnapshotJSON:
+		return writeJSONSnapshot(w, snapshot)
+	case SnapshotBinary:
+		return writeBinarySnapshot(w, snapshot)
+	default:
+		return fmt.Errorf("ecs: unknown snapshot format %d", format)
+	}
+}
+
+// Restore replaces all entities of this world by those of the snapshot (in any format) and sets its context values.
+// Entities get new ids, all EntityID values inside the components, the hierarchy and the relation pairs are remapped to these.
+func (this *ECS) Restore(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	magic, _ := reader.Peek(len(snapshotMagic))
+
+	var snapshot worldSnapshot
+	var err error
+	if slices.Equal(magic, snapshotMagic) {
+		snapshot, err = this.readBinarySnapshot(reader)
+	} else {
+		snapshot, err = this.readJSONSnapshot(reader)
+	}
+	if err != nil {
+		return err
+	}
+	for _, p := range snapshot.pairs {
+		if _, ok := this.registry.Type(p.relation); p.relation != "" && !ok {
+			return fmt.Errorf("%w: relation %q", ErrUnregistered, p.relation)
+		}
+	}
+
+	this.applySnapshot(snapshot)
+	return nil
+}
+
+// collectSnapshot gathers all entities sorted by id, the hierarchy, all relation pairs and all registered context values
+func (this *ECS) collectSnapshot() (worldSnapshot, error) {
+	ids := make([]uint64, 0, len(this.entities))
+	for id := range this.entities {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	entities := make([]snapshotEntity, 0, len(ids))
+	for _, id := range ids {
+		entity := snapshotEntity{id: id}
+		// Read from the storage, holding all changes made in place, in the pointer or value form of the columns
+		if loc, ok := this.components.locations[id]; ok {
+			for i := range loc.archetype.ids {
+				c := loc.archetype.component(i, loc.row)
+				component, ok := this.snapshotComponent(c)
+				if !ok {
+					return worldSnapshot{}, fmt.Errorf("%w: component %T of entity %d", ErrUnregistered, c, id)
+				}
+				entity.components = append(entity.components, component)
+			}
+		}
+		entities = append(entities, entity)
+	}
+
+	contexts := make([]snapshotComponent, 0)
+	for typ := range this.context {
+		// In the form it got added
+		if context, ok := this.snapshotComponent(this.GetContext(typ)); ok {
+			contexts = append(contexts, context)
+		}
+	}
+	slices.SortFunc(contexts, func(a, b snapshotComponent) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	// Parents before their children, in order of attachment
+	pairs := make([]snapshotPair, 0, len(this.parents))
+	for child, parent := range this.Hierarchy() {
+		pairs = append(pairs, snapshotPair{source: child, target: parent})
+	}
+	for _, rId := range slices.Sorted(maps.Keys(this.relations)) {
+		r := this.relations[rId]
+		if len(r.targets) == 0 {
+			continue
+		}
+		info, _ := this.registry.Info(rId)
+		name, ok := this.registry.Name(info.Type)
+		if !ok {
+			return worldSnapshot{}, fmt.Errorf("%w: relation %v", ErrUnregistered, info.Type)
+		}
+		for _, source := range slices.Sorted(maps.Keys(r.targets)) {
+			for _, target := range r.targets[source] {
+				pairs = append(pairs, snapshotPair{relation: name, source: source, target: target})
+			}
+		}
+	}
+
+	return worldSnapshot{entities: entities, contexts: contexts, pairs: pairs}, nil
+}
+
+// snapshotComponent wraps the given value with its registered name
+func (this *ECS) snapshotComponent(c any) (snapshotComponent, bool) {
+	name, ok := this.registry.Name(c)
+	if !ok {
+		return snapshotComponent{}, false
+	}
+	value := reflect.ValueOf(c)
+	pointer := value.Kind() == reflect.Pointer
+	if !pointer {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	return snapshotComponent{name: name, pointer: pointer, value: value}, true
+}
+
+// decodeComponent creates a new value of the registered name and decodes into it
+func (this *ECS) decodeComponent(name string, pointer bool, decode func(ptr any) error) (snapshotComponent, error) {
+	typ, ok := this.registry.Type(name)
+	if !ok {
+		return snapshotComponent{}, fmt.Errorf("%w: %q", ErrUnregistered, name)
+	}
+	value := reflect.New(typ)
+	if err := decode(value.Interface()); err != nil {
+		return snapshotComponent{}, fmt.Errorf("ecs: decoding %q: %w", name, err)
+	}
+	return snapshotComponent{name: name, pointer: pointer, value: value}, nil
+}
+
+// applySnapshot removes all entities and creates the snapshot ones with remapped references
+func (this *ECS) applySnapshot(snapshot worldSnapshot) {
+	// Drop the hierarchy and all pairs first, to neither cascade nor apply cleanup policies
+	clear(this.parents)
+	clear(this.children)
+	for _, r := range this.relations {
+		clear(r.targets)
+		clear(r.sources)
+	}
+	for id := range this.entities {
+		this.RemoveEntityNow(id)
+	}
+	this.toRemove = make([]uint64, 0)
+
+	// Allocate all new ids first, to remap references between them
+	entities := snapshot.entities
+	created := make([]*BaseEntity, len(entities))
+	ids := make(map[EntityID]EntityID, len(entities))
+	for i, e := range entities {
+		created[i] = newEntity(this.entityIds.allocate())
+		ids[EntityID(e.id)] = created[i].Handle()
+	}
+
+	for i, e := range entities {
+		components := make([]any, len(e.components))
+		for j, c := range e.components {
+			remapEntityIDs(c.value.Elem(), ids)
+			components[j] = c.unwrap()
+		}
+		this.createEntity(created[i], components...)
+	}
+
+	for _, p := range snapshot.pairs {
+		source, target := uint64(ids[EntityID(p.source)]), uint64(ids[EntityID(p.target)])
+		if !this.IsAlive(EntityID(source)) || !this.IsAlive(EntityID(target)) {
+			continue
+		}
+		if p.relation == "" {
+			this.SetParent(source, target)
+		} else {
+			typ, _ := this.registry.Type(p.relation)
+			this.relationFor(this.registry.Id(typ)).add(source, target)
+		}
+	}
+
+	for _, c := range snapshot.contexts {
+		remapEntityIDs(c.value.Elem(), ids)
+		this.AddContext(c.unwrap())
+	}
+}
+
+// unwrap returns the value in its original pointer or value form
+func (this snapshotComponent) unwrap() any {
+	if this.pointer {
+		return this.value.Interface()
+	}
+	return this.value.Elem().Interface()
+}
+
+// remapEntityIDs replaces all settable EntityID values by their new ids, unknown ids are zeroed
+func remapEntityIDs(v reflect.Value, ids map[EntityID]EntityID) {
+	if v.Type() == entityIDType {
+		if v.CanSet() {
+			v.SetUint(uint64(ids[EntityID(v.Uint())]))
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			remapEntityIDs(v.Field(i), ids)
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			remapEntityIDs(v.Index(i), ids)
+		}
+	case reflect.Map:
+		if v.Type().Elem() == entityIDType {
+			iter := v.MapRange()
+			for iter.Next() {
+				v.SetMapIndex(iter.Key(), reflect.ValueOf(ids[EntityID(iter.Value().Uint())]))
+			}
+		}
+	default:
+	}
+}
+
+// componentKey prefixes pointer components with a star
+func componentKey(c snapshotComponent) string {
+	if c.pointer {
+		return "*" + c.name
+	}
+	return c.name
+}
+
+type jsonSnapshot struct {
+	Version  int                        `json:"version"`
+	Entities []jsonEntity               `json:"entities"`
+	Pairs    []jsonPair                 `json:"pairs,omitempty"`
+	Context  map[string]json.RawMessage `json:"context,omitempty"`
+}
+
+// jsonPair relates the source to the target, a child to its parent if the relation is omitted
+type jsonPair struct {
+	Relation string `json:"relation,omitempty"`
+	Source   uint64 `json:"source"`
+	Target   uint64 `json:"target"`
+}
+
+type jsonEntity struct {
+	Id         uint64                     `json:"id"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+func writeJSONSnapshot(w io.Writer, world worldSnapshot) error {
+	snapshot := jsonSnapshot{Version: snapshotVersion, Entities: make([]jsonEntity, len(world.entities))}
+	for i, e := range world.entities {
+		snapshot.Entities[i] = jsonEntity{Id: e.id, Components: make(map[string]json.RawMessage, len(e.components))}
+		for _, c := range e.components {
+			data, err := json.Marshal(c.value.Interface())
+			if err != nil {
+				return err
+			}
+			snapshot.Entities[i].Components[componentKey(c)] = data
+		}
+	}
+	for _, p := range world.pairs {
+		snapshot.Pairs = append(snapshot.Pairs, jsonPair{Relation: p.relation, Source: p.source, Target: p.target})
+	}
+	if len(world.contexts) > 0 {
+		snapshot.Context = make(map[string]json.RawMessage, len(world.contexts))
+		for _, c := range world.contexts {
+			data, err := json.Marshal(c.value.Interface())
+			if err != nil {
+				return err
+			}
+			snapshot.Context[componentKey(c)] = data
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+func (this *ECS) readJSONSnapshot(r io.Reader) (worldSnapshot, error) {
+	var snapshot jsonSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return worldSnapshot{}, err
+	}
+	if snapshot.Version != snapshotVersion {
+		return worldSnapshot{}, fmt.Errorf("ecs: unsupported snapshot version %d", snapshot.Version)
+	}
+
+	decodeAll := func(raw map[string]json.RawMessage) ([]snapshotComponent, error) {
+		// Sorted keys for a deterministic component order
+		keys := make([]string, 0, len(raw))
+		for key := range raw {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		components := make([]snapshotComponent, 0, len(raw))
+		for _, key := range keys {
+			name, pointer := strings.CutPrefix(key, "*")
+			c, err := this.decodeComponent(name, pointer, func(ptr any) error {
+				return json.Unmarshal(raw[key], ptr)
+			})
+			if err != nil {
+				return nil, err
+			}
+			components = append(components, c)
+		}
+		return components, nil
+	}
+
+	entities := make([]snapshotEntity, len(snapshot.Entities))
+	for i, e := range snapshot.Entities {
+		components, err := decodeAll(e.Components)
+		if err != nil {
+			return worldSnapshot{}, err
+		}
+		entities[i] = snapshotEntity{id: e.Id, components: components}
+	}
+	contexts, err := decodeAll(snapshot.Context)
+	if err != nil {
+		return worldSnapshot{}, err
+	}
+	pairs := make([]snapshotPair, len(snapshot.Pairs))
+	for i, p := range snapshot.Pairs {
+		pairs[i] = snapshotPair{relation: p.Relation, source: p.Source, target: p.Target}
+	}
+
+	return worldSnapshot{entities: entities, contexts: contexts, pairs: pairs}, nil
+}
+
+type binaryHeader struct {
+	Version  int
+	Names    []string
+	Entities int
+	Contexts int
+	Pairs    []binaryPair
+}
+
+// binaryPair references the relation name of the header, -1 for a child and its parent
+type binaryPair struct {
+	Relation int
+	Source   uint64
+	Target   uint64
+}
+
+// binaryRecord precedes the values of one entity or context, referencing the names of the header
+type binaryRecord struct {
+	Id       uint64
+	Names    []int
+	Pointers []bool
+}
+
+func writeBinarySnapshot(w io.Writer, world worldSnapshot) error {
+	entities, contexts := world.entities, world.contexts
+
+	// One name table for all records and pairs
+	header := binaryHeader{Version: snapshotVersion, Entities: len(entities), Contexts: len(contexts)}
+	names := make(map[string]int)
+	nameIndex := func(name string) int {
+		if _, ok := names[name]; !ok {
+			names[name] = len(header.Names)
+			header.Names = append(header.Names, name)
+		}
+		return names[name]
+	}
+	record := func(id uint64, components []snapshotComponent) binaryRecord {
+		r := binaryRecord{Id: id, Names: make([]int, len(components)), Pointers: make([]bool, len(components))}
+		for i, c := range components {
+			r.Names[i] = nameIndex(c.name)
+			r.Pointers[i] = c.pointer
+		}
+		return r
+	}
+	records := make([]binaryRecord, 0, len(entities)+len(contexts))
+	for _, e := range entities {
+		records = append(records, record(e.id, e.components))
+	}
+	for _, c := range contexts {
+		records = append(records, record(0, []snapshotComponent{c}))
+	}
+	for _, p := range world.pairs {
+		pair := binaryPair{Relation: -1, Source: p.source, Target: p.target}
+		if p.relation != "" {
+			pair.Relation = nameIndex(p.relation)
+		}
+		header.Pairs = append(header.Pairs, pair)
+	}
+
+	if _, err := w.Write(snapshotMagic); err != nil {
+		return err
+	}
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+	writeRecord := func(r binaryRecord, components []snapshotComponent) error {
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+		for _, c := range components {
+			if !gobEncodable(c.value.Type().Elem()) {
+				continue
+			}
+			if err := encoder.EncodeValue(c.value); err != nil {
+				return fmt.Errorf("ecs: encoding %q: %w", c.name, err)
+			}
+		}
+		return nil
+	}
+	for i, e := range entities {
+		if err := writeRecord(records[i], e.components); err != nil {
+			return err
+		}
+	}
+	for i, c := range contexts {
+		if err := writeRecord(records[len(entities)+i], []snapshotComponent{c}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *ECS) readBinarySnapshot(r *bufio.Reader) (worldSnapshot, error) {
+	if _, err := r.Discard(len(snapshotMagic)); err != nil {
+		return worldSnapshot{}, err
+	}
+	decoder := gob.NewDecoder(r)
+	var header binaryHeader
+	if err := decoder.Decode(&header); err != nil {
+		return worldSnapshot{}, err
+	}
+	if header.Version != snapshotVersion {
+		return worldSnapshot{}, fmt.Errorf("ecs: unsupported snapshot version %d", header.Version)
+	}
+
+	readRecord := func() (uint64, []snapshotComponent, error) {
+		var record binaryRecord
+		if err := decoder.Decode(&record); err != nil {
+			return 0, nil, err
+		}
+		components := make([]snapshotComponent, len(record.Names))
+		for i, n := range record.Names {
+			if n < 0 || n >= len(header.Names) {
+				return 0, nil, fmt.Errorf("ecs: invalid name index %d", n)
+			}
+			c, err := this.decodeComponent(header.Names[n], record.Pointers[i], func(ptr any) error {
+				if !gobEncodable(reflect.TypeOf(ptr).Elem()) {
+					return nil
+				}
+				return decoder.Decode(ptr)
+			})
+			if err != nil {
+				return 0, nil, err
+			}
+			components[i] = c
+		}
+		return record.Id, components, nil
+	}
+
+	entities := make([]snapshotEntity, header.Entities)
+	for i := range entities {
+		id, components, err := readRecord()
+		if err != nil {
+			return worldSnapshot{}, err
+		}
+		entities[i] = snapshotEntity{id: id, components: components}
+	}
+	contexts := make([]snapshotComponent, 0, header.Contexts)
+	for i := 0; i < header.Contexts; i++ {
+		_, components, err := readRecord()
+		if err != nil {
+			return worldSnapshot{}, err
+		}
+		contexts = append(contexts, components...)
+	}
+	pairs := make([]snapshotPair, len(header.Pairs))
+	for i, p := range header.Pairs {
+		if p.Relation < -1 || p.Relation >= len(header.Names) {
+			return worldSnapshot{}, fmt.Errorf("ecs: invalid name index %d", p.Relation)
+		}
+		pairs[i] = snapshotPair{source: p.Source, target: p.Target}
+		if p.Relation >= 0 {
+			pairs[i].relation = header.Names[p.Relation]
+		}
+	}
+
+	return worldSnapshot{entities: entities, contexts: contexts, pairs: pairs}, nil
+}
+
+// gobEncodable checks whether gob can encode the type, structs without exported fields (e.g. tags) cannot
+func gobEncodable(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
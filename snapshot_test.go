@@ -0,0 +1,172 @@
+package ecs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+type TargetComponent struct {
+	Target EntityID
+}
+
+type ScoreContext struct {
+	Points int
+}
+
+// createSnapshotWorld is a helper to create a world with registered types and referencing entities
+func createSnapshotWorld() (*ECS, *MoveSystem) {
+	ecs := New()
+	RegisterComponent[PositionComponent](ecs, "position")
+	RegisterComponent[VelocityComponent](ecs, "velocity")
+	RegisterComponent[CommComponent](ecs, "comm")
+	RegisterComponent[TargetComponent](ecs, "target")
+	RegisterComponent[ScoreContext](ecs, "score")
+
+	moveSystem := MoveSystem{}
+	ecs.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
+
+	return ecs, &moveSystem
+}
+
+func Test_Snapshot(t *testing.T) {
+	for _, format := range []SnapshotFormat{SnapshotJSON, SnapshotBinary} {
+		ecs, _ := createSnapshotWorld()
+		ecs.AddContext(&ScoreContext{Points: 42})
+
+		player := createPlayer("player")
+		target := ecs.CreateEntity(&player.PositionComponent, &player.VelocityComponent, CommComponent{})
+		ecs.CreateEntity(TargetComponent{Target: target.(*BaseEntity).Handle()})
+
+		var buffer bytes.Buffer
+		if err := ecs.Snapshot(&buffer, format); err != nil {
+			t.Fatalf("snapshot(%d) err = %v; expected none", format, err)
+		}
+
+		// Restore into a fresh world, which already used some ids
+		restored, moveSystem := createSnapshotWorld()
+		restored.CreateEntity(&PositionComponent{})
+		restored.CreateEntity(&PositionComponent{})
+		if err := restored.Restore(&buffer); err != nil {
+			t.Fatalf("restore(%d) err = %v; expected none", format, err)
+		}
+		restored.Update(33 * time.Millisecond)
+
+		// Assertions
+		if len(restored.entities) != 2 || len(moveSystem.Entities()) != 1 {
+			t.Fatalf("entities(%d, %d); expected (%d, %d)", len(restored.entities), len(moveSystem.Entities()), 2, 1)
+		}
+		for _, targetComponent := range GetComponentsFor[TargetComponent](restored) {
+			if !restored.IsAlive(targetComponent.Target) {
+				t.Errorf("target %d; expected to be remapped", targetComponent.Target)
+			}
+			if p := GetEntityComponent[*PositionComponent](restored, uint64(targetComponent.Target)); p.X != 1+player.DX {
+				t.Errorf("position = %d; expected %d", p.X, 1+player.DX)
+			}
+			if _, ok := restored.components.GetComponent(uint64(targetComponent.Target), CommComponent{}); !ok {
+				t.Errorf("comm component missing; expected to be restored")
+			}
+		}
+		if score := GetContextFor[*ScoreContext](restored); score.Points != 42 {
+			t.Errorf("score = %d; expected %d", score.Points, 42)
+		}
+	}
+}
+
+func Test_Snapshot_InPlaceChanges(t *testing.T) {
+	ecs, _ := createSnapshotWorld()
+	ecs.CreateEntity(PositionComponent{X: 1})
+
+	// Components stored by value are changed in their column
+	NewQuery1[*PositionComponent](ecs).Each(func(id uint64, p *PositionComponent) {
+		p.X = 42
+	})
+	var buffer bytes.Buffer
+	if err := ecs.Snapshot(&buffer, SnapshotJSON); err != nil {
+		t.Fatalf("snapshot err = %v; expected none", err)
+	}
+	restored, _ := createSnapshotWorld()
+	if err := restored.Restore(&buffer); err != nil {
+		t.Fatalf("restore err = %v; expected none", err)
+	}
+
+	// Assertions
+	for _, row := range NewQuery1[PositionComponent](restored).Iter() {
+		if row.A.X != 42 {
+			t.Errorf("position = %d; expected %d", row.A.X, 42)
+		}
+	}
+	if count := NewQuery1[PositionComponent](restored).Count(); count != 1 {
+		t.Errorf("count = %d; expected %d", count, 1)
+	}
+}
+
+func Test_Snapshot_Relations(t *testing.T) {
+	for _, format := range []SnapshotFormat{SnapshotJSON, SnapshotBinary} {
+		ecs, _ := createSnapshotWorld()
+		RegisterComponent[Targets](ecs, "targets")
+		parent := ecs.CreateEntity(&PositionComponent{X: 1}).Id()
+		child1 := ecs.CreateEntity(&PositionComponent{X: 2}).Id()
+		child2 := ecs.CreateEntity(&PositionComponent{X: 3}).Id()
+		ecs.SetParent(child2, parent)
+		ecs.SetParent(child1, parent)
+		AddPair[Targets](ecs, child1, child2)
+
+		var buffer bytes.Buffer
+		if err := ecs.Snapshot(&buffer, format); err != nil {
+			t.Fatalf("snapshot(%d) err = %v; expected none", format, err)
+		}
+		restored, _ := createSnapshotWorld()
+		RegisterComponent[Targets](restored, "targets")
+		restored.CreateEntity(&PositionComponent{})
+		if err := restored.Restore(&buffer); err != nil {
+			t.Fatalf("restore(%d) err = %v; expected none", format, err)
+		}
+
+		// Assertions (children keep their order of attachment)
+		xOf := func(id uint64) int {
+			return GetEntityComponent[*PositionComponent](restored, id).X
+		}
+		var children []int
+		for child, p := range restored.Hierarchy() {
+			if xOf(p) != 1 {
+				t.Errorf("parent = %d; expected %d", xOf(p), 1)
+			}
+			children = append(children, xOf(child))
+		}
+		if len(children) != 2 || children[0] != 3 || children[1] != 2 {
+			t.Errorf("children = %v; expected %v", children, []int{3, 2})
+		}
+		pairs := 0
+		for id := range restored.entities {
+			for target := range TargetsOf[Targets](restored, id) {
+				if xOf(id) != 2 || xOf(target) != 3 {
+					t.Errorf("pair(%d, %d); expected (%d, %d)", xOf(id), xOf(target), 2, 3)
+				}
+				pairs++
+			}
+		}
+		if pairs != 1 {
+			t.Errorf("pairs = %d; expected %d", pairs, 1)
+		}
+	}
+}
+
+func Test_Snapshot_Unregistered(t *testing.T) {
+	ecs, _ := createSnapshotWorld()
+	ecs.CreateEntity(&BoundsComponent{})
+
+	// Assertions
+	if err := ecs.Snapshot(&bytes.Buffer{}, SnapshotJSON); !errors.Is(err, ErrUnregistered) {
+		t.Errorf("err = %v; expected %v", err, ErrUnregistered)
+	}
+
+	// Relations need a registered name, too
+	ecs, _ = createSnapshotWorld()
+	source := ecs.CreateEntity(&PositionComponent{}).Id()
+	AddPair[MemberOf](ecs, source, ecs.CreateEntity(&PositionComponent{}).Id())
+	if err := ecs.Snapshot(&bytes.Buffer{}, SnapshotJSON); !errors.Is(err, ErrUnregistered) {
+		t.Errorf("err = %v; expected %v", err, ErrUnregistered)
+	}
+}
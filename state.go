@@ -0,0 +1,165 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// stateTransitioner is the untyped interface of all state machines, to apply their transitions per update
+type stateTransitioner interface {
+	apply(ecs *ECS, dt time.Duration)
+}
+
+// stateMachine tracks the current and next state of type S with their systems and scoped entities
+type stateMachine[S comparable] struct {
+	current S
+	next    S
+	pending bool
+	entered bool
+
+	onEnter  map[S][]System
+	onExit   map[S][]System
+	entities map[S][]uint64
+}
+
+// apply enters the initial state on the first update and transitions to the next state, if set
+func (this *stateMachine[S]) apply(ecs *ECS, dt time.Duration) {
+	if !this.entered {
+		this.entered = true
+		this.enter(ecs, dt)
+	}
+	if !this.pending {
+		return
+	}
+	this.pending = false
+	if this.next == this.current {
+		return
+	}
+
+	// Exit systems still see the scoped entities, which are removed before the systems run
+	for _, s := range this.onExit[this.current] {
+		ecs.runTransitionSystem(s, dt)
+	}
+	for _, id := range this.entities[this.current] {
+		ecs.RemoveEntity(id)
+	}
+	delete(this.entities, this.current)
+
+	this.current = this.next
+	this.enter(ecs, dt)
+}
+
+// enter makes the current state readable as resource and runs its enter systems
+func (this *stateMachine[S]) enter(ecs *ECS, dt time.Duration) {
+	InsertResource(ecs, this.current)
+	for _, s := range this.onEnter[this.current] {
+		ecs.runTransitionSystem(s, dt)
+	}
+}
+
+// stateMachineFor returns the state machine of S, fails with ErrNoState and returns nil if not added
+func stateMachineFor[S comparable](ecs *ECS) *stateMachine[S] {
+	machine, ok := ecs.stateTypes[reflect.TypeFor[S]()]
+	if !ok {
+		ecs.fail(fmt.Errorf("%w: %v, see AddState", ErrNoState, reflect.TypeFor[S]()))
+		return nil
+	}
+	return machine.(*stateMachine[S])
+}
+
+// applyStates applies the pending transitions of all state machines, in order of their addition
+func (this *ECS) applyStates(dt time.Duration) {
+	for _, machine := range this.states {
+		machine.apply(this, dt)
+	}
+}
+
+// AddState adds a state machine of type S, entering the initial state on the next update.
+// The current state is readable as resource of type S, e.g. via InState.
+func AddState[S comparable](ecs *ECS, initial S) {
+	machine := &stateMachine[S]{
+		current:  initial,
+		onEnter:  make(map[S][]System),
+		onExit:   make(map[S][]System),
+		entities: make(map[S][]uint64),
+	}
+	ecs.states = append(ecs.states, machine)
+	ecs.stateTypes[reflect.TypeFor[S]()] = machine
+	InsertResource(ecs, initial)
+}
+
+// SetNextState queues a transition, applied at the start of the next update before any entity removal and system run
+func SetNextState[S comparable](ecs *ECS, next S) {
+	machine := stateMachineFor[S](ecs)
+	if machine == nil {
+		return
+	}
+	machine.next = next
+	machine.pending = true
+}
+
+// State returns the current state of type S, the zero value if not added
+func State[S comparable](ecs *ECS) S {
+	machine := stateMachineFor[S](ecs)
+	if machine == nil {
+		var zero S
+		return zero
+	}
+	return machine.current
+}
+
+// OnEnter adds a system under the given types, running once whenever the state is entered instead of on every update
+func OnEnter[S comparable](ecs *ECS, state S, s System, types ...any) {
+	machine := stateMachineFor[S](ecs)
+	if machine == nil || !ecs.addTransitionSystem(s, types...) {
+		return
+	}
+	machine.onEnter[state] = append(machine.onEnter[state], s)
+}
+
+// OnExit adds a system under the given types, running once whenever the state is exited instead of on every update
+func OnExit[S comparable](ecs *ECS, state S, s System, types ...any) {
+	machine := stateMachineFor[S](ecs)
+	if machine == nil || !ecs.addTransitionSystem(s, types...) {
+		return
+	}
+	machine.onExit[state] = append(machine.onExit[state], s)
+}
+
+// OnUpdate adds a system under the given types, running on every update while in the given state
+func OnUpdate[S comparable](ecs *ECS, state S, s System, types ...any) {
+	if stateMachineFor[S](ecs) == nil {
+		return
+	}
+	ecs.systems.AddSystem(s, types...)
+	if !ecs.attachSystem(s) {
+		return
+	}
+	ecs.conditions[s] = append(ecs.conditions[s], InState(state))
+}
+
+// ScopeToState removes the entity via RemoveEntity when the given state is exited
+func ScopeToState[S comparable](ecs *ECS, id uint64, state S) {
+	machine := stateMachineFor[S](ecs)
+	if machine == nil {
+		return
+	}
+	if !slices.Contains(machine.entities[state], id) {
+		machine.entities[state] = append(machine.entities[state], id)
+	}
+}
+
+// addTransitionSystem adds a system which tracks its entities, but is not scheduled and only runs on state transitions
+func (this *ECS) addTransitionSystem(s System, types ...any) bool {
+	this.systems.addUnscheduledSystem(s, types...)
+	return this.attachSystem(s)
+}
+
+// runTransitionSystem runs the enter or exit system, unless disabled
+func (this *ECS) runTransitionSystem(s System, dt time.Duration) {
+	if !this.disabled[s] {
+		this.runSystem(s, dt)
+	}
+}
@@ -0,0 +1,69 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_State(t *testing.T) {
+	ecs := New()
+	AddState(ecs, GameStateMenu)
+
+	enterSystem := StepSystem{}
+	exitSystem := StepSystem{}
+	playSystem := StepSystem{}
+	OnEnter(ecs, GameStatePlaying, &enterSystem)
+	OnExit(ecs, GameStateMenu, &exitSystem)
+	OnUpdate(ecs, GameStatePlaying, &playSystem)
+
+	menu := ecs.CreateEntity(&PositionComponent{}).Id()
+	ScopeToState(ecs, menu, GameStateMenu)
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if State[GameState](ecs) != GameStateMenu || enterSystem.runs+exitSystem.runs+playSystem.runs != 0 {
+		t.Errorf("runs(%d, %d, %d); expected none in the menu", enterSystem.runs, exitSystem.runs, playSystem.runs)
+	}
+	SetNextState(ecs, GameStatePlaying)
+	if State[GameState](ecs) != GameStateMenu {
+		t.Errorf("state = %v; expected %v until the next update", State[GameState](ecs), GameStateMenu)
+	}
+	ecs.Update(33 * time.Millisecond)
+	ecs.Update(33 * time.Millisecond)
+	if State[GameState](ecs) != GameStatePlaying {
+		t.Errorf("state = %v; expected %v", State[GameState](ecs), GameStatePlaying)
+	}
+	if enterSystem.runs != 1 || exitSystem.runs != 1 || playSystem.runs != 2 {
+		t.Errorf("runs(%d, %d, %d); expected (%d, %d, %d)", enterSystem.runs, exitSystem.runs, playSystem.runs, 1, 1, 2)
+	}
+	if ecs.IsAlive(EntityID(menu)) {
+		t.Errorf("menu alive = %v; expected to be removed on exit", true)
+	}
+}
+
+func Test_State_TransitionSystems(t *testing.T) {
+	ecs := NewParallel()
+	defer ecs.Close()
+	AddState(ecs, GameStateMenu)
+
+	// Transition systems are not scheduled and do not split the groups of update systems
+	enterSystem := StepSystem{}
+	playSystem := StepSystem{}
+	OnEnter(ecs, GameStatePlaying, &enterSystem, &PositionComponent{})
+	OnUpdate(ecs, GameStatePlaying, &playSystem, Read[*PositionComponent]())
+	ecs.CreateEntity(&PositionComponent{})
+
+	// Assertions
+	if groups := ecs.AllParallel(); len(groups) != 1 || len(groups[0]) != 1 {
+		t.Errorf("parallelSystems = %v; expected only the update system", groups)
+	}
+	if len(enterSystem.Entities()) != 1 {
+		t.Errorf("entities = %d; expected %d, still tracked", len(enterSystem.Entities()), 1)
+	}
+	ecs.SetSystemEnabled(&enterSystem, false)
+	SetNextState(ecs, GameStatePlaying)
+	ecs.Update(33 * time.Millisecond)
+	if enterSystem.runs != 0 || playSystem.runs != 1 {
+		t.Errorf("runs(%d, %d); expected (%d, %d) with the enter system disabled", enterSystem.runs, playSystem.runs, 0, 1)
+	}
+}
@@ -0,0 +1,66 @@
+package ecs
+
+import (
+	"sync"
+)
+
+// SyncWorld queues structural changes from other goroutines through a channel-backed inbox, drained at the start of every
+// update of its world. Readers may access the world concurrently via Read, excluded from running updates.
+// Sending blocks while the inbox is full until the next update drains it, so systems (and anything else running on the
+// updating goroutine) must not send, but use their command buffer instead, see ECS.Commands.
+type SyncWorld struct {
+	ecs   *ECS
+	mutex sync.RWMutex
+
+	inbox  chan command
+	buffer *CommandBuffer
+}
+
+// NewSyncWorld attaches an inbox of the given capacity to the world, sending blocks while the inbox is full
+func NewSyncWorld(ecs *ECS, capacity int) (this *SyncWorld) {
+	this = new(SyncWorld)
+	this.ecs = ecs
+	this.inbox = make(chan command, capacity)
+	this.buffer = NewCommandBuffer(ecs)
+	ecs.syncWorld = this
+	return this
+}
+
+// CreateEntity queues the creation of an entity with the given components and returns its reserved id
+func (this *SyncWorld) CreateEntity(components ...any) uint64 {
+	entity := newEntity(this.ecs.entityIds.allocate())
+	this.inbox <- command{kind: commandCreateEntity, entity: entity, components: components}
+	return entity.Id()
+}
+
+// RemoveEntity queues the removal of an entity
+func (this *SyncWorld) RemoveEntity(id uint64) {
+	this.inbox <- command{kind: commandRemoveEntity, id: id}
+}
+
+// AddComponents queues adding components to an entity
+func (this *SyncWorld) AddComponents(id uint64, components ...any) {
+	this.inbox <- command{kind: commandAddComponents, id: id, components: components}
+}
+
+// RemoveComponents queues removing components (or types) from an entity
+func (this *SyncWorld) RemoveComponents(id uint64, components ...any) {
+	this.inbox <- command{kind: commandRemoveComponents, id: id, components: components}
+}
+
+// Read calls fn with the world while no update is running, concurrently with other readers. fn must not change the world.
+func (this *SyncWorld) Read(fn func(ecs *ECS)) {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	fn(this.ecs)
+}
+
+// drain applies the changes queued so far, the write lock must be held.
+// Changes queued meanwhile wait for the next update, to not starve it under steady producers.
+func (this *SyncWorld) drain() {
+	for n := len(this.inbox); n > 0; n-- {
+		this.buffer.commands = append(this.buffer.commands, <-this.inbox)
+	}
+	this.buffer.Playback()
+}
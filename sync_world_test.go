@@ -0,0 +1,86 @@
+package ecs
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_SyncWorld(t *testing.T) {
+	ecs := New()
+	moveSystem := MoveSystem{}
+	ecs.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
+	world := NewSyncWorld(ecs, 16)
+
+	// Spawn from several goroutines while updating
+	var wg sync.WaitGroup
+	ids := make(chan uint64, 100)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 25 {
+				ids <- world.CreateEntity(&PositionComponent{}, &VelocityComponent{DX: 1})
+				world.Read(func(ecs *ECS) {
+					_ = len(ecs.entities)
+				})
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			ecs.Update(time.Millisecond)
+		}
+	}
+	ecs.Update(time.Millisecond)
+
+	// Assertions
+	if len(ecs.entities) != 100 || len(moveSystem.Entities()) != 100 {
+		t.Errorf("entities(%d, %d); expected %d", len(ecs.entities), len(moveSystem.Entities()), 100)
+	}
+	world.RemoveEntity(<-ids)
+	if len(ecs.entities) != 100 {
+		t.Errorf("entities = %d; expected %d until the next update", len(ecs.entities), 100)
+	}
+	ecs.Update(time.Millisecond)
+	if len(ecs.entities) != 99 {
+		t.Errorf("entities = %d; expected %d", len(ecs.entities), 99)
+	}
+}
+
+func Test_SyncWorld_Drain(t *testing.T) {
+	ecs := New()
+	world := NewSyncWorld(ecs, 4)
+	for range 4 {
+		world.CreateEntity(&PositionComponent{})
+	}
+
+	// A steady producer does not keep the update draining
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 4 {
+			world.CreateEntity(&PositionComponent{})
+		}
+	}()
+	ecs.Update(time.Millisecond)
+
+	// Assertions
+	if len(ecs.entities) != 4 {
+		t.Errorf("entities = %d; expected %d queued before the update", len(ecs.entities), 4)
+	}
+	wg.Wait()
+	ecs.Update(time.Millisecond)
+	if len(ecs.entities) != 8 {
+		t.Errorf("entities = %d; expected %d", len(ecs.entities), 8)
+	}
+}
@@ -13,6 +13,17 @@ type System interface {
 
 type EntitySystem struct {
 	entities []uint64
+
+	// optional scheduling declarations
+	stage  Stage
+	fixed  bool
+	before []System
+	after  []System
+
+	// optional run conditions, all have to be met, and the run rate
+	conditions []func(ecs *ECS) bool
+	every      int
+	frames     int
 }
 
 func (this *EntitySystem) Entities() []uint64 {
@@ -44,3 +55,67 @@ func (this *EntitySystem) detachEntityById(eId uint64) {
 func (this *EntitySystem) Priority() int {
 	return 0
 }
+
+// Stage returns the stage this system runs in, StageUpdate by default
+func (this *EntitySystem) Stage() Stage {
+	return this.stage
+}
+
+// InStage declares the stage this system runs in, call before adding the system
+func (this *EntitySystem) InStage(stage Stage) {
+	this.stage = stage
+}
+
+// Fixed returns whether this system runs on the fixed timestep of a Runner
+func (this *EntitySystem) Fixed() bool {
+	return this.fixed
+}
+
+// InFixedStep declares this system to run on the fixed timestep of a Runner, call before adding the system
+func (this *EntitySystem) InFixedStep() {
+	this.fixed = true
+}
+
+// Before returns the systems this system has to run before
+func (this *EntitySystem) Before() []System {
+	return this.before
+}
+
+// RunBefore declares systems this system has to run before, call before adding the system
+func (this *EntitySystem) RunBefore(systems ...System) {
+	this.before = append(this.before, systems...)
+}
+
+// After returns the systems this system has to run after
+func (this *EntitySystem) After() []System {
+	return this.after
+}
+
+// RunAfter declares systems this system has to run after, call before adding the system
+func (this *EntitySystem) RunAfter(systems ...System) {
+	this.after = append(this.after, systems...)
+}
+
+// RunIf declares a condition to be met for this system to run, evaluated on every update
+func (this *EntitySystem) RunIf(condition func(ecs *ECS) bool) {
+	this.conditions = append(this.conditions, condition)
+}
+
+// RunEvery declares this system to run only every n-th update its conditions are met, starting with the first
+func (this *EntitySystem) RunEvery(n int) {
+	this.every = n
+}
+
+// ShouldRun evaluates the run conditions and rate of this system, once per update
+func (this *EntitySystem) ShouldRun(ecs *ECS) bool {
+	for _, condition := range this.conditions {
+		if !condition(ecs) {
+			return false
+		}
+	}
+	if this.every > 1 {
+		this.frames++
+		return (this.frames-1)%this.every == 0
+	}
+	return true
+}
@@ -9,10 +9,22 @@ import (
 type SystemStorage struct {
 	ecs *ECS
 
-	// n systems could be registered
+	// n systems could be registered, in order of registration
+	registered []System
+	// the scheduled systems, by stage, declared order and priority
 	systems []System
+	// per system, the systems declared to run before it
+	predecessors map[System][]System
+	// the error of the last scheduling, if any
+	err error
 	// per system, n types it requires
 	systemTypes map[System][]reflect.Type
+	// per system, n plain types an entity must not have
+	systemExcludes map[System][]reflect.Type
+	// per system, the accessed types and whether they are written
+	systemAccess map[System]map[accessKey]bool
+	// systems only tracking their entities, not scheduled to run
+	unscheduled map[System]bool
 	// group systems without overlapping types to parallelize
 	parallel        bool
 	parallelSystems [][]System
@@ -23,6 +35,9 @@ func NewSystemStorage(ecs *ECS, parallel bool) (this *SystemStorage) {
 	this.ecs = ecs
 	this.parallel = parallel
 	this.systemTypes = make(map[System][]reflect.Type)
+	this.systemExcludes = make(map[System][]reflect.Type)
+	this.systemAccess = make(map[System]map[accessKey]bool)
+	this.unscheduled = make(map[System]bool)
 	return
 }
 
@@ -33,12 +48,22 @@ func NewParallelSystemStorage(ecs *ECS) (this *SystemStorage) {
 
 // Clear nils all systems
 func (this *SystemStorage) Clear() {
+	this.registered = nil
 	this.systems = nil
+	this.predecessors = nil
 	this.systemTypes = nil
+	this.systemExcludes = nil
+	this.systemAccess = nil
+	this.unscheduled = nil
 	this.parallelSystems = nil
 }
 
-// All returns all systems
+// Err returns the error of the last scheduling, e.g. a cycle in the declared system order
+func (this *SystemStorage) Err() error {
+	return this.err
+}
+
+// All returns all systems in their scheduled order
 func (this *SystemStorage) All() []System {
 	return this.systems
 }
@@ -48,49 +73,87 @@ func (this *SystemStorage) AllParallel() [][]System {
 	return this.parallelSystems
 }
 
-// AddSystem stores the given system under every type to this storage
+// AddSystem stores the given system under every type (or Querier) to this storage
 func (this *SystemStorage) AddSystem(system System, types ...any) []reflect.Type {
 	// add to slice
-	this.systems = append(this.systems, system)
-	this.systemTypes[system] = make([]reflect.Type, len(types))
-	for i, t := range types {
-		// add to types
-		this.systemTypes[system][i] = reflect.TypeOf(t) //this.ecs.getPlainType(t)
+	this.registered = append(this.registered, system)
+	this.systemTypes[system] = make([]reflect.Type, 0, len(types))
+	this.systemAccess[system] = make(map[accessKey]bool)
+	for _, t := range types {
+		// add to types, queries and access declarations bring their own
+		switch t := t.(type) {
+		case Querier:
+			this.systemTypes[system] = append(this.systemTypes[system], t.Types()...)
+			this.systemExcludes[system] = append(this.systemExcludes[system], t.Excluded()...)
+			for _, access := range t.Access() {
+				this.addAccess(system, access)
+			}
+		case Access:
+			if !t.Resource {
+				this.systemTypes[system] = append(this.systemTypes[system], t.Type)
+			}
+			this.addAccess(system, t)
+		default:
+			// plain types are written by default
+			this.systemTypes[system] = append(this.systemTypes[system], reflect.TypeOf(t)) //this.ecs.getPlainType(t)
+			this.addAccess(system, Access{Type: reflect.TypeOf(t), Write: true})
+		}
+	}
+	// systems may declare further access
+	if declarer, ok := system.(AccessDeclarer); ok {
+		for _, access := range declarer.Access() {
+			this.addAccess(system, access)
+		}
 	}
 
 	// Sort
-	this.sort()
-	this.parallelize()
+	this.schedule()
 
 	return this.systemTypes[system]
 }
 
+// addUnscheduledSystem stores the given system to track its entities, without scheduling it to run
+func (this *SystemStorage) addUnscheduledSystem(system System, types ...any) []reflect.Type {
+	this.unscheduled[system] = true
+	return this.AddSystem(system, types...)
+}
+
 // RemoveSystem slices the given system out of every type from this storage
 func (this *SystemStorage) RemoveSystem(system System) {
 	// delete from slice
-	for i, s := range this.systems {
-		if s == system {
-			this.systems = append(this.systems[:i], this.systems[i+1:]...)
-		}
-	}
+	this.registered = slices.DeleteFunc(this.registered, func(s System) bool {
+		return s == system
+	})
 
 	// delete types
 	delete(this.systemTypes, system)
+	delete(this.systemExcludes, system)
+	delete(this.systemAccess, system)
+	delete(this.unscheduled, system)
 
 	// Sort
-	this.sort()
-	this.parallelize()
+	this.schedule()
 }
 
-// sort systems by priority (higher = better)
+// addAccess merges the given access into the system access, writes win
+func (this *SystemStorage) addAccess(system System, access Access) {
+	key := access.key()
+	this.systemAccess[system][key] = this.systemAccess[system][key] || access.Write
+}
+
+// sort returns the registered systems by priority (higher = better)
 func (this *SystemStorage) sort() []System {
-	slices.SortStableFunc(this.systems, func(a, b System) int {
+	systems := slices.DeleteFunc(slices.Clone(this.registered), func(s System) bool {
+		return this.unscheduled[s]
+	})
+	slices.SortStableFunc(systems, func(a, b System) int {
 		return cmp.Compare(b.Priority(), a.Priority())
 	})
-	return this.systems
+	return systems
 }
 
-// parallelize compares all systems against each other to build a 2-dim slice of parallel runnable systems
+// parallelize assigns every scheduled system a level after all its predecessors and conflicting systems of its stage,
+// to build a 2-dim slice of parallel runnable systems
 func (this *SystemStorage) parallelize() [][]System {
 	if !this.parallel {
 		return this.parallelSystems
@@ -98,32 +161,42 @@ func (this *SystemStorage) parallelize() [][]System {
 
 	// Check whether systems can run in parallel anew
 	this.parallelSystems = make([][]System, 0)
-	systems := append([]System(nil), this.systems...)
-	var pSystems []System
-	for len(systems) > 0 {
-		pSystems, systems = this.testSystemsOverlap(systems[0], systems)
-		this.parallelSystems = append(this.parallelSystems, pSystems)
+	levels := make(map[System]int, len(this.systems))
+	offset := 0
+	for i, s := range this.systems {
+		// A new stage starts after all groups of the previous one
+		if i > 0 && stageOf(s) != stageOf(this.systems[i-1]) {
+			offset = len(this.parallelSystems)
+		}
+
+		level := offset
+		for _, p := range this.systems[:i] {
+			if stageOf(p) != stageOf(s) {
+				continue
+			}
+			if slices.Contains(this.predecessors[s], p) || this.testAccessConflict(p, s) {
+				level = max(level, levels[p]+1)
+			}
+		}
+		levels[s] = level
+
+		if level == len(this.parallelSystems) {
+			this.parallelSystems = append(this.parallelSystems, make([]System, 0))
+		}
+		this.parallelSystems[level] = append(this.parallelSystems[level], s)
 	}
 	return this.parallelSystems
 }
 
-// testSystemsOverlap compares systemA vs all other systems to find commonalities
-func (this *SystemStorage) testSystemsOverlap(a System, systems []System) (pSystems []System, otherSystems []System) {
-	// The parallel systems to run with a
-	pSystems = append(pSystems, a)
-
-	// Check against all others
-	for i := 1; i < len(systems); i++ {
-		b := systems[i]
-
-		// In case of no type equality, we can run in parallel (no overlap)
-		if !this.testTypesOverlap(this.systemTypes[a], this.systemTypes[b]) {
-			pSystems = append(pSystems, b)
-		} else {
-			otherSystems = append(otherSystems, b)
+// testAccessConflict checks whether both systems access a common type and at least one of them writes it
+func (this *SystemStorage) testAccessConflict(a, b System) bool {
+	accessB := this.systemAccess[b]
+	for t, writeA := range this.systemAccess[a] {
+		if writeB, ok := accessB[t]; ok && (writeA || writeB) {
+			return true
 		}
 	}
-	return pSystems, otherSystems
+	return false
 }
 
 // QuerySystems returns all systems matching all given types connotations
@@ -136,8 +209,8 @@ func (this *SystemStorage) QuerySystems(types ...any) []System {
 		reflectTypes[i] = reflect.TypeOf(t) //this.ecs.getPlainType(t))
 	}
 
-	for system, systemTypes := range this.systemTypes {
-		if this.testTypesSubset(systemTypes, reflectTypes) {
+	for system := range this.systemTypes {
+		if this.testSystemMatch(system, reflectTypes) {
 			systems = append(systems, system)
 		}
 	}
@@ -145,6 +218,21 @@ func (this *SystemStorage) QuerySystems(types ...any) []System {
 	return systems
 }
 
+// testSystemMatch checks if the given entity types contain all types of the system, but none of its excludes
+func (this *SystemStorage) testSystemMatch(system System, types []reflect.Type) bool {
+	if !this.testTypesSubset(this.systemTypes[system], types) {
+		return false
+	}
+	for _, excluded := range this.systemExcludes[system] {
+		for _, t := range types {
+			if plainType(t) == excluded {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // testTypesSubset checks if the needle is fully contained in the haystack
 func (this *SystemStorage) testTypesSubset(needle, haystack []reflect.Type) bool {
 	set := make(map[reflect.Type]int, len(haystack))
@@ -202,3 +290,14 @@ func (this *SystemStorage) intersectSystems(a, b []System) []System {
 	}
 	return intersection
 }
+
+// differenceSystems returns the systems of a, which are not in b
+func (this *SystemStorage) differenceSystems(a, b []System) []System {
+	difference := make([]System, 0)
+	for _, s1 := range a {
+		if !slices.Contains(b, s1) {
+			difference = append(difference, s1)
+		}
+	}
+	return difference
+}
@@ -3,6 +3,7 @@ package ecs
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_IntersectSystems(t *testing.T) {
@@ -149,9 +150,31 @@ func Test_Parallelize(t *testing.T) {
 	storage.AddSystem(&movePtrSystem, PositionComponent{}, &VelocityComponent{})
 	storage.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
 
+	// Assertions (used to be 2 groups, but the value and pointer forms share one column
+	// and collision conflicts with the higher prioritized move, it may not run ahead of it)
+	if len(storage.parallelSystems) != 3 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.parallelSystems), 3)
+	}
+	if storage.parallelSystems[0][0] != &movePtrSystem || storage.parallelSystems[2][0] != &collisionSystem {
+		t.Errorf("parallelSystems = %v; expected %v first and %v last", storage.parallelSystems, &movePtrSystem, &collisionSystem)
+	}
+}
+
+func Test_Parallelize_NoConflicts(t *testing.T) {
+	ecs := New()
+	storage := NewParallelSystemStorage(ecs)
+
+	// Without conflicts, lower priorities may run alongside
+	collisionSystem := CollisionSystem{}
+	movePtrSystem := MoveWithoutPtrSystem{}
+	moveSystem := MoveSystem{}
+	storage.AddSystem(&collisionSystem, &BoundsComponent{})
+	storage.AddSystem(&movePtrSystem, &CommComponent{})
+	storage.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{})
+
 	// Assertions
-	if len(storage.parallelSystems) != 2 {
-		t.Errorf("parallelSystems = %v; expected %v", len(storage.parallelSystems), 2)
+	if len(storage.parallelSystems) != 1 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.parallelSystems), 1)
 	}
 }
 
@@ -171,3 +194,60 @@ func Test_NoParallelize(t *testing.T) {
 		t.Errorf("parallelSystems = %v; expected %v", len(storage.parallelSystems), 0)
 	}
 }
+
+type ReadBoundsSystem struct {
+	EntitySystem
+}
+
+func (this *ReadBoundsSystem) Run(ecs *ECS, dt time.Duration) {
+}
+
+func (this *ReadBoundsSystem) Access() []Access {
+	return []Access{Read[*BoundsComponent]()}
+}
+
+func Test_Parallelize_Access(t *testing.T) {
+	ecs := New()
+	storage := NewParallelSystemStorage(ecs)
+
+	// Two readers of the same component can run in parallel
+	collisionSystem := CollisionSystem{}
+	moveSystem := MoveSystem{}
+	storage.AddSystem(&collisionSystem, Read[*PositionComponent](), Read[*BoundsComponent]())
+	storage.AddSystem(&moveSystem, Read[*PositionComponent](), Write[*VelocityComponent]())
+
+	// Assertions
+	if len(storage.AllParallel()) != 1 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.AllParallel()), 1)
+	}
+
+	// A writer conflicts with readers, the lower prioritized collision has to wait for it
+	movePtrSystem := MoveWithoutPtrSystem{}
+	storage.AddSystem(&movePtrSystem, Write[*PositionComponent]())
+
+	// Assertions
+	if len(storage.AllParallel()) != 3 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.AllParallel()), 3)
+	}
+
+	// Pointer and value forms of a component are the same column
+	storage = NewParallelSystemStorage(ecs)
+	storage.AddSystem(&moveSystem, Write[*PositionComponent]())
+	storage.AddSystem(&collisionSystem, Read[PositionComponent]())
+
+	// Assertions
+	if len(storage.AllParallel()) != 2 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.AllParallel()), 2)
+	}
+
+	// Declared access is considered, too
+	storage = NewParallelSystemStorage(ecs)
+	readBoundsSystem := ReadBoundsSystem{}
+	storage.AddSystem(&collisionSystem, Read[*PositionComponent](), Write[*BoundsComponent]())
+	storage.AddSystem(&readBoundsSystem, CommComponent{})
+
+	// Assertions
+	if len(storage.AllParallel()) != 2 {
+		t.Errorf("parallelSystems = %v; expected %v", len(storage.AllParallel()), 2)
+	}
+}
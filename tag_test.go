@@ -0,0 +1,94 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+)
+
+type StunnedComponent struct {
+	Reason string
+}
+
+func Test_Tag(t *testing.T) {
+	ecs := New()
+
+	player1 := createPlayer("player1")
+	ecs.CreateEntity(&player1.PositionComponent, &player1.VelocityComponent, CommComponent{})
+	player2 := createPlayer("player2")
+	ecs.CreateEntity(&player2.PositionComponent, &player2.VelocityComponent)
+
+	// Assertions
+	if !ecs.Registry().IsTag(CommComponent{}) || ecs.Registry().IsTag(PositionComponent{}) {
+		t.Errorf("tags(%v, %v); expected (true, false)", ecs.Registry().IsTag(CommComponent{}), ecs.Registry().IsTag(PositionComponent{}))
+	}
+	for _, a := range ecs.components.Archetypes() {
+		if a.Has(typeOf(CommComponent{})) && len(a.columns) != 2 {
+			t.Errorf("columns = %d; expected %d without the tag", len(a.columns), 2)
+		}
+	}
+	if count := NewQuery1[*PositionComponent](ecs).With(CommComponent{}).Count(); count != 1 {
+		t.Errorf("with = %d; expected %d", count, 1)
+	}
+	if count := NewQuery1[CommComponent](ecs).Count(); count != 1 {
+		t.Errorf("fetch = %d; expected %d", count, 1)
+	}
+	if components := ecs.GetComponents(CommComponent{}); len(components) != 1 {
+		t.Errorf("components = %d; expected %d", len(components), 1)
+	}
+}
+
+func Test_Tag_Toggle(t *testing.T) {
+	ecs := New()
+	Tag[StunnedComponent](ecs)
+
+	moveSystem := MoveSystem{}
+	ecs.AddSystem(&moveSystem, &PositionComponent{}, &VelocityComponent{}, StunnedComponent{})
+
+	player := createPlayer("player")
+	id := ecs.CreateEntity(&player.PositionComponent, &player.VelocityComponent).Id()
+	ecs.AddTag(id, StunnedComponent{Reason: "dropped"})
+	ecs.Update(33 * time.Millisecond)
+
+	// Assertions
+	if len(moveSystem.Entities()) != 1 {
+		t.Errorf("entities = %d; expected %d", len(moveSystem.Entities()), 1)
+	}
+	if stunned := GetEntityComponent[StunnedComponent](ecs, id); stunned.Reason != "" {
+		t.Errorf("reason = %q; expected the value not to be stored", stunned.Reason)
+	}
+	ecs.RemoveTag(id, StunnedComponent{})
+	if len(moveSystem.Entities()) != 0 || NewQuery1[*PositionComponent](ecs).With(StunnedComponent{}).Count() != 0 {
+		t.Errorf("entities = %d; expected %d", len(moveSystem.Entities()), 0)
+	}
+	if p := GetEntityComponent[*PositionComponent](ecs, id); p != &player.PositionComponent || p.X != 1+player.DX {
+		t.Errorf("position = %d; expected %d, kept on toggle", p.X, 1+player.DX)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("recover = %v; expected a panic for stored components", r)
+		}
+	}()
+	Tag[PositionComponent](ecs)
+}
+
+func Test_Tag_Toggle_Empty(t *testing.T) {
+	ecs := New()
+	Tag[StunnedComponent](ecs)
+
+	// Entities without components are stored on their first tag
+	id := ecs.CreateEntity().Id()
+	ecs.AddTag(id, StunnedComponent{})
+
+	// Assertions
+	if count := NewQuery1[StunnedComponent](ecs).Count(); count != 1 {
+		t.Errorf("count = %d; expected %d", count, 1)
+	}
+	ecs.RemoveTag(id, StunnedComponent{})
+	if count := NewQuery1[StunnedComponent](ecs).Count(); count != 0 {
+		t.Errorf("count = %d; expected %d", count, 0)
+	}
+	ecs.AddTag(id, StunnedComponent{})
+	if count := NewQuery1[StunnedComponent](ecs).Count(); count != 1 {
+		t.Errorf("count = %d; expected %d after removing the last tag", count, 1)
+	}
+}